@@ -0,0 +1,72 @@
+/*
+Copyright © 2023 GALLEZ Romain
+*/
+package cmd
+
+import (
+	"github.com/romaingallez/clim_cli/internals/commands"
+	"github.com/spf13/cobra"
+)
+
+// adminCmd represents the admin command
+var adminCmd = &cobra.Command{
+	Use:   "admin",
+	Short: "Adapter maintenance: LED, holiday mode, reboot and clock",
+	Long: `Run maintenance actions on the wifi adapters.
+
+Examples:
+  clim_cli admin led off --group "coté10"
+  clim_cli admin holiday on --all
+  clim_cli admin reboot --ip 192.168.1.20
+  clim_cli admin sync-time --all`,
+}
+
+var adminLEDCmd = &cobra.Command{
+	Use:       "led on|off",
+	Short:     "Turn the adapter status LED on or off",
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: []string{"on", "off"},
+	Run:       commands.AdminLED,
+}
+
+var adminHolidayCmd = &cobra.Command{
+	Use:       "holiday on|off",
+	Short:     "Enable or disable holiday mode",
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: []string{"on", "off"},
+	Run:       commands.AdminHoliday,
+}
+
+var adminRebootCmd = &cobra.Command{
+	Use:   "reboot",
+	Short: "Restart the adapter",
+	Long: `Restart the wifi adapters. The units keep running, but cannot be reached
+until the adapter is back (about a minute).
+
+Rebooting more than one adapter (--group, --all) asks for confirmation;
+pass --yes to skip it in scripts.`,
+	Args: cobra.NoArgs,
+	Run:  commands.AdminReboot,
+}
+
+var adminSyncTimeCmd = &cobra.Command{
+	Use:   "sync-time",
+	Short: "Set the adapter clock to the local date and time",
+	Args:  cobra.NoArgs,
+	Run:   commands.AdminSyncTime,
+}
+
+func init() {
+	rootCmd.AddCommand(adminCmd)
+	adminCmd.AddCommand(adminLEDCmd)
+	adminCmd.AddCommand(adminHolidayCmd)
+	adminCmd.AddCommand(adminRebootCmd)
+	adminCmd.AddCommand(adminSyncTimeCmd)
+
+	for _, c := range []*cobra.Command{adminLEDCmd, adminHolidayCmd, adminRebootCmd, adminSyncTimeCmd} {
+		c.Flags().StringP("ip", "", "", "IP address (overrides global default)")
+		c.Flags().StringP("group", "g", "", "Group name (grp_name) of stored devices to target")
+		c.Flags().BoolP("all", "a", false, "Target all stored devices")
+	}
+	adminRebootCmd.Flags().BoolP("yes", "y", false, "Do not ask for confirmation when rebooting several adapters")
+}
@@ -4,6 +4,8 @@ Copyright © 2023 GALLEZ Romain
 package cmd
 
 import (
+	"time"
+
 	"github.com/romaingallez/clim_cli/internals/commands"
 	"github.com/spf13/cobra"
 )
@@ -35,7 +37,10 @@ func init() {
 	batchCmd.Flags().StringP("power", "p", "", "Power setting (0 or 1)")
 	batchCmd.Flags().StringP("mode", "m", "", "Mode setting (0=AUTO, 1=HEAT, 2=DRY, 3=FAN, 4=COOL)")
 	batchCmd.Flags().StringP("temp", "t", "", "Temperature setting (e.g., 22.0)")
-	batchCmd.Flags().StringP("fan-rate", "r", "", "Fan rate (A or 3-7)")
+	batchCmd.Flags().StringP("fan-rate", "r", "", "Fan rate (A, B=quiet or 3-7, as supported by each unit)")
 	batchCmd.Flags().StringP("fan-dir", "d", "", "Fan direction (0=all wings stopped, 1=vertical, 2=horizontal, 3=both)")
+	batchCmd.Flags().StringSlice("special", nil, "Special mode: powerful|econo|streamer[=on|off] (repeatable)")
+	batchCmd.Flags().Bool("verify", false, "Read the settings back from each unit and report fields it did not take")
+	batchCmd.Flags().Duration("verify-timeout", 10*time.Second, "How long to wait for each unit to report the settings")
 }
 
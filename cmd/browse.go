@@ -11,7 +11,6 @@ import (
 	"github.com/romaingallez/clim_cli/internals/config"
 	"github.com/romaingallez/clim_cli/internals/tui"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 // browseCmd represents the browse command
@@ -34,12 +33,11 @@ and show historical information including change tracking.`,
 			fmt.Printf("\nSelected %d device(s):\n", len(selectedDevices))
 			for i, device := range selectedDevices {
 				fmt.Printf("%d. %s (%s)\n", i+1, device.Device.Name, device.Device.IP)
-				viper.Set("ip", device.Device.IP)
-				viper.Set("name", device.Device.Name)
 			}
 
 			// Persist the last selected device to the default config file
-			if err := config.SaveConfig(); err != nil {
+			last := selectedDevices[len(selectedDevices)-1].Device
+			if err := config.SaveDefaultDevice(last.IP, last.Name); err != nil {
 				log.Printf("Warning: Failed to save selected device to config: %v", err)
 			} else {
 				cfgPath := filepath.Join(config.GetConfigDir(), config.ConfigFileName+"."+config.ConfigFileType)
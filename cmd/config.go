@@ -6,6 +6,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/romaingallez/clim_cli/internals/config"
 	"github.com/spf13/cobra"
@@ -45,6 +46,16 @@ var configShowCmd = &cobra.Command{
 		fmt.Printf("Fan Rate: %s\n", cfg.FanRate)
 		fmt.Printf("Search Timeout: %d\n", cfg.Search.Timeout)
 		fmt.Printf("Search Workers: %d\n", cfg.Search.Workers)
+		fmt.Printf("Search Match Vendors: %s\n", strings.Join(cfg.Search.Match.Vendors, ", "))
+		fmt.Printf("Search Match OUI Prefixes: %s\n", strings.Join(cfg.Search.Match.OUIPrefixes, ", "))
+		fmt.Printf("Search Match Probe: %t\n", cfg.Search.Match.Probe)
+		fmt.Printf("API Timeout: %s\n", cfg.API.Timeout)
+		fmt.Printf("API Max Attempts: %d\n", cfg.API.MaxAttempts)
+		fmt.Printf("API Retry Delay: %s to %s\n", cfg.API.RetryBaseDelay, cfg.API.RetryMaxDelay)
+		fmt.Printf("API Retry Budget: %d\n", cfg.API.RetryBudget)
+		fmt.Printf("Storage Backend: %s\n", cfg.Storage.Backend)
+		fmt.Printf("Storage Retention: max %d snapshots, max age %s, only on change %t\n",
+			cfg.Storage.Retention.MaxSnapshots, cfg.Storage.Retention.MaxAge, cfg.Storage.Retention.OnlyOnChange)
 		fmt.Printf("\nConfig Directory: %s\n", config.GetConfigDir())
 	},
 }
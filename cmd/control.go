@@ -5,6 +5,7 @@ import (
 	"log"
 	"strings"
 
+	"github.com/romaingallez/clim_cli/internals/commands"
 	"github.com/romaingallez/clim_cli/internals/storage"
 	"github.com/romaingallez/clim_cli/internals/tui"
 	"github.com/spf13/cobra"
@@ -54,7 +55,7 @@ var controlCmd = &cobra.Command{
 			return
 		}
 
-		if err := tui.RunControlScreen(selected); err != nil {
+		if err := tui.RunControlScreen(commands.NewAPIClient(), selected); err != nil {
 			log.Fatalf("Error running control TUI: %v", err)
 		}
 	},
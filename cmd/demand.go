@@ -0,0 +1,64 @@
+/*
+Copyright © 2023 GALLEZ Romain
+*/
+package cmd
+
+import (
+	"github.com/romaingallez/clim_cli/internals/api"
+	"github.com/romaingallez/clim_cli/internals/commands"
+	"github.com/spf13/cobra"
+)
+
+// demandCmd represents the demand command
+var demandCmd = &cobra.Command{
+	Use:   "demand",
+	Short: "Manage demand control (compressor power cap)",
+	Long: `Manage demand control, which caps the compressor power of the units.
+
+'set' caps the power at a fixed percentage of the rated power. The per-day schedule
+fields (scdl_per_day, moc...suc) are shown by 'get' and written back unchanged.
+
+` + api.ExperimentalNote + `
+'set' and 'off' require --experimental.
+
+Examples:
+  clim_cli demand get --all
+  clim_cli demand set --group "coté10" --percent 70 --experimental
+  clim_cli demand off --group "coté10" --experimental`,
+}
+
+var demandGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Show the demand control settings",
+	Run:   commands.DemandGet,
+}
+
+var demandSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set a fixed power cap",
+	Run:   commands.DemandSet,
+}
+
+var demandOffCmd = &cobra.Command{
+	Use:   "off",
+	Short: "Remove the power cap",
+	Run:   commands.DemandOff,
+}
+
+func init() {
+	rootCmd.AddCommand(demandCmd)
+	demandCmd.AddCommand(demandGetCmd)
+	demandCmd.AddCommand(demandSetCmd)
+	demandCmd.AddCommand(demandOffCmd)
+
+	for _, c := range []*cobra.Command{demandGetCmd, demandSetCmd, demandOffCmd} {
+		c.Flags().StringP("ip", "", "", "IP address (overrides global default)")
+		c.Flags().StringP("group", "g", "", "Group name (grp_name) of stored devices to target")
+		c.Flags().BoolP("all", "a", false, "Target all stored devices")
+	}
+
+	for _, c := range []*cobra.Command{demandSetCmd, demandOffCmd} {
+		c.Flags().Bool("experimental", false, "Confirm writing to the undocumented demand control endpoint")
+	}
+	demandSetCmd.Flags().Int("percent", 0, "Fixed power cap in percent of the rated power (40-100)")
+}
@@ -0,0 +1,87 @@
+/*
+Copyright © 2023 GALLEZ Romain
+*/
+package cmd
+
+import (
+	"github.com/romaingallez/clim_cli/internals/commands"
+	"github.com/spf13/cobra"
+)
+
+// deviceCmd represents the device command
+var deviceCmd = &cobra.Command{
+	Use:   "device",
+	Short: "Manage device identity (name and group) and registration",
+	Long: `Manage the name and group (grp_name) stored on the adapters, and
+register this terminal with HTTPS adapters.
+
+Changes are written to the devices and recorded in the local device
+history. Bulk mode reads a CSV file with a "mac" column and a "name"
+and/or "group" column: rename and set-group use their own column, import
+applies both.
+
+Examples:
+  clim_cli device rename --ip 192.168.1.20 "Office 12"
+  clim_cli device set-group --mac aa:bb:cc:dd:ee:ff "coté10"
+  clim_cli device rename --csv devices.csv
+  clim_cli device set-group --csv devices.csv
+  clim_cli device import devices.csv
+  clim_cli device register --ip 192.168.1.30 --key 0123456789012`,
+}
+
+var deviceRenameCmd = &cobra.Command{
+	Use:   "rename [name]",
+	Short: "Rename a device",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   commands.DeviceRename,
+}
+
+var deviceImportCmd = &cobra.Command{
+	Use:   "import <file.csv>",
+	Short: "Rename and regroup devices from a CSV file",
+	Long: `Apply the names and groups of a CSV file with "mac", "name" and
+"group" columns. Either value column may be missing and empty cells are
+left unchanged, so one file can rename some devices and regroup others.`,
+	Args: cobra.ExactArgs(1),
+	Run:  commands.DeviceImport,
+}
+
+var deviceRegisterCmd = &cobra.Command{
+	Use:   "register",
+	Short: "Register this terminal with an HTTPS adapter (BRP072C)",
+	Long: `Register a terminal UUID with an adapter that only speaks HTTPS.
+
+The adapter key is printed on its label. The scheme, UUID and key are
+stored with the device, and every later request to it uses HTTPS and
+sends the UUID. The adapter's self-signed certificate is accepted for
+this device only.`,
+	Args: cobra.NoArgs,
+	Run:  commands.DeviceRegister,
+}
+
+var deviceSetGroupCmd = &cobra.Command{
+	Use:   "set-group [group]",
+	Short: "Change the group name (grp_name) of a device",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   commands.DeviceSetGroup,
+}
+
+func init() {
+	rootCmd.AddCommand(deviceCmd)
+	deviceCmd.AddCommand(deviceRenameCmd)
+	deviceCmd.AddCommand(deviceSetGroupCmd)
+	deviceCmd.AddCommand(deviceImportCmd)
+	deviceCmd.AddCommand(deviceRegisterCmd)
+
+	for _, c := range []*cobra.Command{deviceRenameCmd, deviceSetGroupCmd} {
+		c.Flags().StringP("ip", "", "", "IP address (overrides global default)")
+		c.Flags().String("mac", "", "MAC address of a stored device")
+		c.Flags().String("csv", "", "CSV file with mac and name/group columns for bulk changes")
+	}
+
+	deviceRegisterCmd.Flags().StringP("ip", "", "", "IP address (overrides global default)")
+	deviceRegisterCmd.Flags().String("mac", "", "MAC address of a stored device")
+	deviceRegisterCmd.Flags().String("key", "", "Adapter key printed on its label")
+	deviceRegisterCmd.Flags().String("scheme", "https", "Scheme used to reach the adapter (http or https)")
+	deviceRegisterCmd.Flags().String("uuid", "", "Terminal UUID to register (default: stored or newly generated)")
+}
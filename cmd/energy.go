@@ -0,0 +1,36 @@
+/*
+Copyright © 2023 GALLEZ Romain
+*/
+package cmd
+
+import (
+	"github.com/romaingallez/clim_cli/internals/commands"
+	"github.com/spf13/cobra"
+)
+
+// energyCmd represents the energy command
+var energyCmd = &cobra.Command{
+	Use:   "energy",
+	Short: "Report energy consumption for today, the last 7 days and this year",
+	Long: `Report energy consumption read from the adapters' power counters.
+
+Totals are given per device, per group (grp_name) and for the whole fleet,
+for today, the last seven days (a rolling window, not the calendar week)
+and the current year, in kWh. A counter the adapter cannot report is left
+empty with its error, and the other counters are still shown.
+
+Examples:
+  clim_cli energy --all
+  clim_cli energy --group "coté10" --format csv > energy.csv
+  clim_cli energy --all --format json`,
+	Run: commands.EnergyClim,
+}
+
+func init() {
+	rootCmd.AddCommand(energyCmd)
+
+	energyCmd.Flags().StringP("ip", "", "", "IP address (overrides global default)")
+	energyCmd.Flags().StringP("group", "g", "", "Group name (grp_name) of stored devices to report")
+	energyCmd.Flags().BoolP("all", "a", false, "Report all stored devices")
+	energyCmd.Flags().StringP("format", "f", "table", "Output format: table, csv or json")
+}
@@ -0,0 +1,54 @@
+/*
+Copyright © 2023 GALLEZ Romain
+*/
+package cmd
+
+import (
+	"github.com/romaingallez/clim_cli/internals/commands"
+	"github.com/spf13/cobra"
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history [device]",
+	Short: "Show the changes recorded for stored devices",
+	Long: `Show the changes recorded each time devices are saved (search, device rename, ...)
+as a time-ordered log across devices, or for a single device given by MAC, IP or name.
+
+--field keeps the given fields and the fields nested under them: "control_info"
+matches every control_info.* change. It may be repeated or comma-separated.
+
+Examples:
+  clim_cli history --since 24h
+  clim_cli history "Bureau 1" --field control_info.stemp
+  clim_cli history --group "coté10" --since 168h --format csv > changes.csv
+  clim_cli history diff "Bureau 1" 2025-01-10 now`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  commands.History,
+}
+
+var historyDiffCmd = &cobra.Command{
+	Use:   "diff <device> <t1> <t2>",
+	Short: "Compare two snapshots of a device field by field",
+	Long: `Compare the snapshots of a device taken at or before t1 and t2, field by field,
+sensor readings included.
+
+Times are "now", a duration before now ("24h") or a local date and time
+("2025-01-10", "2025-01-10 14:30", or RFC 3339). A date alone means the end of that day.
+
+Examples:
+  clim_cli history diff 192.168.1.20 48h now
+  clim_cli history diff a0:c9:a0:12:34:56 "2025-01-10 08:00" "2025-01-10 18:00" --format json`,
+	Args: cobra.ExactArgs(3),
+	Run:  commands.HistoryDiff,
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.AddCommand(historyDiffCmd)
+
+	historyCmd.PersistentFlags().StringP("format", "f", "table", "Output format: table, csv or json")
+	historyCmd.Flags().Duration("since", 0, "Only show changes made in this window, e.g. 24h (default: all)")
+	historyCmd.Flags().StringSlice("field", nil, "Only show changes of these fields, e.g. control_info.stemp")
+	historyCmd.Flags().StringP("group", "g", "", "Only show devices of this group (grp_name)")
+}
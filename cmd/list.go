@@ -4,6 +4,8 @@ Copyright © 2023 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"fmt"
+
 	"github.com/romaingallez/clim_cli/internals/tui"
 	"github.com/spf13/cobra"
 )
@@ -18,7 +20,7 @@ This command displays a summary of all stored climate devices
 including their IP addresses, status, and change history.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := tui.PrintDeviceSummary(); err != nil {
-			// Error is already printed by PrintDeviceSummary
+			fmt.Printf("Error: %v\n", err)
 		}
 	},
 }
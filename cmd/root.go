@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/romaingallez/clim_cli/internals/commands"
 	"github.com/romaingallez/clim_cli/internals/config"
 	"github.com/romaingallez/clim_cli/internals/version"
 	"github.com/spf13/cobra"
@@ -28,6 +29,13 @@ var rootCmd = &cobra.Command{
 		// If no subcommand and no version flag, show help
 		cmd.Help()
 	},
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Flags and args are valid by now: errors below are not usage errors
+		cmd.SilenceUsage = true
+		record, _ := cmd.Flags().GetString("record")
+		replay, _ := cmd.Flags().GetString("replay")
+		return commands.UseCassette(record, replay)
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -55,6 +63,10 @@ func init() {
 	rootCmd.PersistentFlags().StringP("fan-dir", "d", "", "default fan direction (0=all wings stopped, 1=vertical, 2=horizontal, 3=both)")
 	rootCmd.PersistentFlags().StringP("fan-rate", "r", "", "default fan rate")
 
+	// Record/replay of the adapter HTTP traffic; not bound to Viper so they never end up in the config file
+	rootCmd.PersistentFlags().String("record", "", "record every adapter request and response to a cassette in this directory")
+	rootCmd.PersistentFlags().String("replay", "", "answer adapter requests from the cassette in this directory instead of the network")
+
 	// Version flag
 	rootCmd.Flags().BoolP("version", "v", false, "Print version information")
 
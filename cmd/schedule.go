@@ -0,0 +1,91 @@
+/*
+Copyright © 2023 GALLEZ Romain
+*/
+package cmd
+
+import (
+	"github.com/romaingallez/clim_cli/internals/api"
+	"github.com/romaingallez/clim_cli/internals/commands"
+	"github.com/spf13/cobra"
+)
+
+// scheduleCmd represents the schedule command
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Manage climate schedules",
+}
+
+// scheduleDeviceCmd manages the weekly program stored on the adapters
+var scheduleDeviceCmd = &cobra.Command{
+	Use:   "device",
+	Short: "Read and write the weekly program stored on the adapters",
+	Long: `Read and write the weekly program (device-side schedule) stored on the adapters.
+
+` + api.ExperimentalNote + `
+'push' and 'copy' require --experimental.
+
+Programs are exchanged as YAML files:
+
+  enabled: true
+  days:
+    mon:
+      - time: "08:00"
+        power: "1"
+        mode: "4"
+        temp: "22.0"
+      - time: "18:30"
+        power: "0"
+
+Examples:
+  clim_cli schedule device export --ip 192.168.1.20 -o office.yaml
+  clim_cli schedule device validate office.yaml
+  clim_cli schedule device push office.yaml --group "coté10" --experimental
+  clim_cli schedule device copy --from 192.168.1.20 --group "coté10" --days mon-fri --experimental`,
+}
+
+var scheduleDeviceExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the weekly program of a device to YAML",
+	Run:   commands.ScheduleExport,
+}
+
+var scheduleDeviceValidateCmd = &cobra.Command{
+	Use:   "validate <file>",
+	Short: "Validate a weekly program YAML file",
+	Args:  cobra.ExactArgs(1),
+	Run:   commands.ScheduleValidate,
+}
+
+var scheduleDevicePushCmd = &cobra.Command{
+	Use:   "push <file>",
+	Short: "Write a weekly program YAML file to one or many devices",
+	Args:  cobra.ExactArgs(1),
+	Run:   commands.SchedulePush,
+}
+
+var scheduleDeviceCopyCmd = &cobra.Command{
+	Use:   "copy",
+	Short: "Copy the weekly program of one device to other devices",
+	Run:   commands.ScheduleCopy,
+}
+
+func init() {
+	rootCmd.AddCommand(scheduleCmd)
+	scheduleCmd.AddCommand(scheduleDeviceCmd)
+	scheduleDeviceCmd.AddCommand(scheduleDeviceExportCmd)
+	scheduleDeviceCmd.AddCommand(scheduleDeviceValidateCmd)
+	scheduleDeviceCmd.AddCommand(scheduleDevicePushCmd)
+	scheduleDeviceCmd.AddCommand(scheduleDeviceCopyCmd)
+
+	scheduleDeviceExportCmd.Flags().StringP("ip", "", "", "IP address (overrides global default)")
+	scheduleDeviceExportCmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
+
+	for _, c := range []*cobra.Command{scheduleDevicePushCmd, scheduleDeviceCopyCmd} {
+		c.Flags().StringP("ip", "", "", "IP address (overrides global default)")
+		c.Flags().StringP("group", "g", "", "Group name (grp_name) of stored devices to target")
+		c.Flags().BoolP("all", "a", false, "Target all stored devices")
+		c.Flags().String("days", "", "Only replace these days, e.g. mon-fri or sat,sun (default: whole week)")
+		c.Flags().Bool("experimental", false, "Confirm writing to the undocumented program endpoint")
+	}
+	scheduleDeviceCopyCmd.Flags().String("from", "", "IP address of the device to copy the program from")
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/romaingallez/clim_cli/internals/commands"
+	"github.com/romaingallez/clim_cli/internals/search"
 	"github.com/spf13/cobra"
 )
 
@@ -15,14 +16,24 @@ import (
 var searchCmd = &cobra.Command{
 	Use:   "search",
 	Short: "search for climate devices on the network",
-	Long: `Search for climate devices on the local network using arp-scan.
+	Long: `Search for climate devices on the local network.
 
 This command discovers climate devices, saves them to local storage with historical
 tracking, and optionally launches an interactive TUI for device selection.
 
-Notes:
-- Requires arp-scan (Debian/Ubuntu: apt-get install arp-scan; macOS: brew install arp-scan)
-- Workers control parallel HTTP info fetches; arp-scan itself runs once
+Methods (--method):
+- udp: broadcast the adapter discovery message; needs no privileges or extra tools
+- arp: arp-scan filtered by the search.match config (OUI prefixes, vendor substrings,
+  "murata" by default); needs root and arp-scan
+  (Debian/Ubuntu: apt-get install arp-scan; macOS: brew install arp-scan)
+  --probe also keeps unmatched hosts whose basic_info reports type=aircon;
+  --all-vendors --probe ignores the vendor rules and confirms every host by its response
+- sweep: probe /common/basic_info on every host of the --cidr prefixes and keep the
+  hosts answering ret=OK; reaches routed subnets where broadcasts are filtered
+Without --method, sweep is used when --cidr is given; otherwise arp when arp-scan is
+installed and running as root (or with --all-vendors), udp otherwise.
+
+Workers control parallel HTTP info fetches; the discovery itself runs once.
 
 Devices are stored with timestamps to track changes over time. Use --tui flag
 for interactive selection sorted by device name.`,
@@ -40,6 +51,11 @@ func init() {
 	searchCmd.Flags().IntP("timeout", "", 5, "timeout in seconds for each device check")
 	searchCmd.Flags().IntP("workers", "w", 10, "number of concurrent workers")
 	searchCmd.Flags().Bool("tui", false, "launch interactive TUI for device selection")
+	searchCmd.Flags().String("method", "", "discovery method: udp, arp or sweep (default: sweep with --cidr, arp when available as root, else udp)")
+	searchCmd.Flags().StringSlice("cidr", nil, "IPv4 prefixes to sweep, e.g. 10.0.12.0/24,10.0.13.0/24")
+	searchCmd.Flags().Duration("sweep-timeout", search.SweepTimeout, "time given to each swept or probed host to answer")
+	searchCmd.Flags().Bool("probe", false, "arp: keep unmatched hosts whose basic_info reports type=aircon")
+	searchCmd.Flags().Bool("all-vendors", false, "arp: ignore the vendor and OUI rules and confirm every host by probing it")
 }
 
 // getDefaultInterface returns the name of the default network interface
@@ -0,0 +1,34 @@
+/*
+Copyright © 2023 GALLEZ Romain
+*/
+package cmd
+
+import (
+	"github.com/romaingallez/clim_cli/internals/commands"
+	"github.com/spf13/cobra"
+)
+
+// sensorsCmd represents the sensors command
+var sensorsCmd = &cobra.Command{
+	Use:   "sensors",
+	Short: "Read room and outdoor temperature sensors",
+	Long: `Read sensor values (room temperature, humidity, outdoor temperature,
+compressor frequency) from one device, a group or all stored devices.
+
+The last reading is stored with each known device so 'list' and the TUIs
+can show the room temperature next to the setpoint.
+
+Examples:
+  clim_cli sensors --ip 192.168.1.20
+  clim_cli sensors --group "coté10"
+  clim_cli sensors --all`,
+	Run: commands.SensorsClim,
+}
+
+func init() {
+	rootCmd.AddCommand(sensorsCmd)
+
+	sensorsCmd.Flags().StringP("ip", "", "", "IP address (overrides global default)")
+	sensorsCmd.Flags().StringP("group", "g", "", "Group name (grp_name) of stored devices to read")
+	sensorsCmd.Flags().BoolP("all", "a", false, "Read all stored devices")
+}
@@ -4,6 +4,8 @@ Copyright © 2023 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"time"
+
 	"github.com/romaingallez/clim_cli/internals/commands"
 	"github.com/romaingallez/clim_cli/internals/config"
 	"github.com/spf13/cobra"
@@ -27,6 +29,9 @@ func init() {
 	setCmd.Flags().StringP("temp", "", "", "temperature setting (overrides global default)")
 	setCmd.Flags().StringP("fan-dir", "", "", "fan direction: 0=all wings stopped, 1=vertical, 2=horizontal, 3=both (overrides global default)")
 	setCmd.Flags().StringP("fan-rate", "", "", "fan rate (overrides global default)")
+	setCmd.Flags().StringSlice("special", nil, "special mode: powerful|econo|streamer[=on|off] (repeatable)")
+	setCmd.Flags().Bool("verify", false, "read the settings back and report fields the unit did not take (exit status 3)")
+	setCmd.Flags().Duration("verify-timeout", 10*time.Second, "how long to wait for the unit to report the settings")
 
 	// Bind local flags as well so they override Viper
 	config.BindFlags(setCmd)
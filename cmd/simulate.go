@@ -0,0 +1,44 @@
+/*
+Copyright © 2023 GALLEZ Romain
+*/
+package cmd
+
+import (
+	"github.com/romaingallez/clim_cli/internals/commands"
+	"github.com/spf13/cobra"
+)
+
+// simulateCmd represents the simulate command
+var simulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Run fake adapters on local ports for testing",
+	Long: `Start fake wifi adapters on consecutive local ports, to develop scripts and batch
+files without touching real units.
+
+Each adapter serves common/basic_info, aircon/get_control_info, aircon/set_control_info
+and aircon/get_sensor_info. set_control_info validates its parameters like the adapters
+(ret=PARAM NG on missing or bad values) and the room temperature drifts toward the
+setpoint while the unit runs. Use --latency and --fail-rate to inject slow responses
+and failures (503 on reads, ret=SERIAL NG on writes).
+
+With --register the adapters are discovered and saved to storage in the "Simulated"
+group, so --group Simulated works with batch, sensors, admin... They can also be
+found with 'clim_cli search --cidr 127.0.0.1:18080,127.0.0.1:18081'.
+
+Examples:
+  clim_cli simulate --count 3 --register
+  clim_cli simulate --port 19000 --latency 300ms --fail-rate 0.1`,
+	Args: cobra.NoArgs,
+	Run:  commands.Simulate,
+}
+
+func init() {
+	rootCmd.AddCommand(simulateCmd)
+
+	simulateCmd.Flags().IntP("count", "c", 3, "number of adapters to simulate")
+	simulateCmd.Flags().String("listen", "127.0.0.1", "address the adapters listen on")
+	simulateCmd.Flags().Int("port", 18080, "port of the first adapter; the next ones use the following ports (0 picks free ports)")
+	simulateCmd.Flags().Duration("latency", 0, "delay added before every response")
+	simulateCmd.Flags().Float64("fail-rate", 0, "probability (0-1) that a request fails")
+	simulateCmd.Flags().Bool("register", false, "save the simulated adapters to storage")
+}
@@ -0,0 +1,79 @@
+/*
+Copyright © 2023 GALLEZ Romain
+*/
+package cmd
+
+import (
+	"github.com/romaingallez/clim_cli/internals/commands"
+	"github.com/spf13/cobra"
+)
+
+// storageCmd represents the storage command
+var storageCmd = &cobra.Command{
+	Use:   "storage",
+	Short: "Manage the device storage",
+	Long: `Manage the device storage.
+
+Devices are stored in devices.json by default, rewritten as a whole on every save.
+Set 'storage.backend: bolt' in the config file to use the embedded database devices.db
+instead, indexed by MAC, IP, group and time.
+
+The storage format carries a schema version. Older files are migrated in memory when
+loaded and written in the current format on the next save; 'migrate' upgrades them at once.
+
+Archived snapshots are kept according to storage.retention; 'prune' applies the limits and
+folds the dropped snapshots into daily summaries.
+
+Examples:
+  clim_cli storage migrate --dry-run
+  clim_cli storage prune --max-snapshots 100 --max-age 720h --dry-run
+  clim_cli storage import-json
+  clim_cli storage import-json ./backup/devices.json --db /tmp/devices.db`,
+}
+
+var storageImportJSONCmd = &cobra.Command{
+	Use:   "import-json [devices.json]",
+	Short: "Copy a devices.json file into the embedded database",
+	Long: `Copy every device of a devices.json file (the current one by default) into the
+embedded database, snapshots and changes included. Devices already in the database
+are replaced.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  commands.StorageImportJSON,
+}
+
+var storageMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade the stored data to the current schema version",
+	Long: `Run the storage migrations needed to bring the stored data to the schema version of
+this clim_cli, and report each step with the number of devices it changes.
+With --dry-run nothing is written.`,
+	Args: cobra.NoArgs,
+	Run:  commands.StorageMigrate,
+}
+
+var storagePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Drop old snapshots, keeping daily summaries",
+	Long: `Drop the archived snapshots beyond the retention limits (storage.retention in the
+config file, overridden by --max-snapshots and --max-age). Dropped snapshots are
+downsampled into one summary per device and day: snapshot count, IPs, power-on count,
+modes and average set and room temperatures.
+With --dry-run nothing is written.`,
+	Args: cobra.NoArgs,
+	Run:  commands.StoragePrune,
+}
+
+func init() {
+	rootCmd.AddCommand(storageCmd)
+	storageCmd.AddCommand(storageImportJSONCmd)
+	storageCmd.AddCommand(storageMigrateCmd)
+	storageCmd.AddCommand(storagePruneCmd)
+
+	storageMigrateCmd.Flags().Bool("dry-run", false, "report what would change without writing")
+
+	storagePruneCmd.Flags().Bool("dry-run", false, "report what would be dropped without writing")
+	storagePruneCmd.Flags().Int("max-snapshots", 0, "archived snapshots kept per device (default: storage.retention.max_snapshots)")
+	storagePruneCmd.Flags().Duration("max-age", 0, "drop archived snapshots older than this (default: storage.retention.max_age)")
+
+	storageImportJSONCmd.Flags().String("db", "", "database file to import into (default: devices.db in the config directory)")
+}
@@ -0,0 +1,62 @@
+/*
+Copyright © 2023 GALLEZ Romain
+*/
+package cmd
+
+import (
+	"github.com/romaingallez/clim_cli/internals/commands"
+	"github.com/spf13/cobra"
+)
+
+// timerCmd represents the timer command
+var timerCmd = &cobra.Command{
+	Use:   "timer",
+	Short: "Manage the on/off timers stored on the adapters",
+	Long: `Manage the on/off delay timers stored on the adapters.
+
+Timers run on the adapter itself, so "off in 2 hours" works even when
+no computer stays online.
+
+Examples:
+  clim_cli timer get --ip 192.168.1.20
+  clim_cli timer set --group "coté10" --off 2h
+  clim_cli timer set --ip 192.168.1.20 --on 30m --off 8h30m
+  clim_cli timer clear --all`,
+}
+
+var timerGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Show the on/off timers",
+	Run:   commands.TimerGet,
+}
+
+var timerSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set on and/or off delays",
+	Run:   commands.TimerSet,
+}
+
+var timerClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Disable the on/off timers",
+	Run:   commands.TimerClear,
+}
+
+func init() {
+	rootCmd.AddCommand(timerCmd)
+	timerCmd.AddCommand(timerGetCmd)
+	timerCmd.AddCommand(timerSetCmd)
+	timerCmd.AddCommand(timerClearCmd)
+
+	for _, c := range []*cobra.Command{timerGetCmd, timerSetCmd, timerClearCmd} {
+		c.Flags().StringP("ip", "", "", "IP address (overrides global default)")
+		c.Flags().StringP("group", "g", "", "Group name (grp_name) of stored devices to target")
+		c.Flags().BoolP("all", "a", false, "Target all stored devices")
+	}
+
+	timerSetCmd.Flags().Duration("on", 0, "Turn on after this delay (e.g. 30m, 1h30m)")
+	timerSetCmd.Flags().Duration("off", 0, "Turn off after this delay (e.g. 2h)")
+
+	timerClearCmd.Flags().Bool("on", false, "Only clear the on timer")
+	timerClearCmd.Flags().Bool("off", false, "Only clear the off timer")
+}
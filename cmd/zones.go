@@ -0,0 +1,57 @@
+/*
+Copyright © 2023 GALLEZ Romain
+*/
+package cmd
+
+import (
+	"github.com/romaingallez/clim_cli/internals/commands"
+	"github.com/spf13/cobra"
+)
+
+// zonesCmd represents the zones command
+var zonesCmd = &cobra.Command{
+	Use:   "zones",
+	Short: "List and switch the ducted zones of AirBase units",
+	Long: `List and switch the ducted zones of units behind an AirBase (skyfi) adapter.
+
+Zones are selected by number (as listed) or by name.
+
+Examples:
+  clim_cli zones list --ip 192.168.1.40
+  clim_cli zones on --ip 192.168.1.40 2 "Bedroom"
+  clim_cli zones off --group "ducted" 3`,
+}
+
+var zonesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the zones and their state",
+	Args:  cobra.NoArgs,
+	Run:   commands.ZonesList,
+}
+
+var zonesOnCmd = &cobra.Command{
+	Use:   "on <zone>...",
+	Short: "Turn zones on",
+	Args:  cobra.MinimumNArgs(1),
+	Run:   commands.ZonesOn,
+}
+
+var zonesOffCmd = &cobra.Command{
+	Use:   "off <zone>...",
+	Short: "Turn zones off",
+	Args:  cobra.MinimumNArgs(1),
+	Run:   commands.ZonesOff,
+}
+
+func init() {
+	rootCmd.AddCommand(zonesCmd)
+	zonesCmd.AddCommand(zonesListCmd)
+	zonesCmd.AddCommand(zonesOnCmd)
+	zonesCmd.AddCommand(zonesOffCmd)
+
+	for _, c := range []*cobra.Command{zonesListCmd, zonesOnCmd, zonesOffCmd} {
+		c.Flags().StringP("ip", "", "", "IP address (overrides global default)")
+		c.Flags().StringP("group", "g", "", "Group name (grp_name) of stored devices to target")
+		c.Flags().BoolP("all", "a", false, "Target all stored devices")
+	}
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// SetLED turns the adapter status LED on or off using context.
+func (c *Client) SetLED(ctx context.Context, ip string, on bool) error {
+	_, err := c.get(ctx, ip, "common/set_led", "led="+boolParam(on))
+	return err
+}
+
+// SetHoliday enables or disables holiday mode using context.
+// While enabled, the unit ignores its weekly program and stays off.
+func (c *Client) SetHoliday(ctx context.Context, ip string, on bool) error {
+	_, err := c.get(ctx, ip, "common/set_holiday", "en_hol="+boolParam(on))
+	return err
+}
+
+// Reboot restarts the adapter using context. The unit keeps its settings
+// but is unreachable for a few seconds.
+func (c *Client) Reboot(ctx context.Context, ip string) error {
+	_, err := c.get(ctx, ip, "common/reboot", "")
+	return err
+}
+
+// NotifyDateTime pushes the given local time to the adapter clock using context.
+// The adapter clock drives the weekly program and the on/off timers.
+func (c *Client) NotifyDateTime(ctx context.Context, ip string, now time.Time) error {
+	query := url.Values{"cur": {now.Format("2006/1/2 15:04:05")}}
+	_, err := c.get(ctx, ip, "common/notify_date_time", query.Encode())
+	return err
+}
@@ -3,142 +3,99 @@ package api
 import (
 	"context"
 	"fmt"
-	"io"
-	"net"
-	"net/http"
 	"net/url"
 	"strings"
-	"time"
 )
 
-var httpClient = &http.Client{
-	Timeout: 4 * time.Second,
-	Transport: &http.Transport{
-		Proxy:               http.ProxyFromEnvironment,
-		DialContext:         (&net.Dialer{Timeout: 3 * time.Second, KeepAlive: 30 * time.Second}).DialContext,
-		TLSHandshakeTimeout: 3 * time.Second,
-		MaxIdleConns:        100,
-		IdleConnTimeout:     90 * time.Second,
-		MaxIdleConnsPerHost: 10,
-	},
-}
-
 // SetClim performs a control update with context and returns an error on failure.
-func SetClim(ctx context.Context, clim Clim) error {
+// A *RetError is returned when the adapter answers with a non-OK ret status.
+func (c *Client) SetClim(ctx context.Context, clim Clim) error {
+	fanRate := clim.FanRate
+	mode := clim.Mode
+	extra := ""
+	if c.endpoint(clim.IP).Dialect == DialectSkyfi {
+		mode = skyfiModeToWire(clim.Mode)
+		var fanAuto string
+		fanRate, fanAuto = skyfiFanRateToWire(clim.FanRate)
+		extra = "&f_auto=" + url.QueryEscape(fanAuto) + "&lpw="
+	}
 	query := fmt.Sprintf("pow=%s&stemp=%s&mode=%s&shum=%s&f_rate=%s&f_dir=%s",
 		url.QueryEscape(clim.Power),
 		url.QueryEscape(clim.Temp),
-		url.QueryEscape(clim.Mode),
+		url.QueryEscape(mode),
 		url.QueryEscape(clim.Shum),
-		url.QueryEscape(clim.FanRate),
+		url.QueryEscape(fanRate),
 		url.QueryEscape(clim.FanDir),
-	)
-	u := &url.URL{Scheme: "http", Host: clim.IP, Path: "aircon/set_control_info", RawQuery: query}
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
-	if err != nil {
-		return err
-	}
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		return err
-	}
-	if resp == nil {
-		return fmt.Errorf("nil http response")
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		b, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("http %d: %s", resp.StatusCode, string(b))
-	}
-	return nil
+	) + extra
+	_, err := c.get(ctx, clim.IP, "aircon/set_control_info", query)
+	return err
 }
 
-// FetchControlInfo fetches control info using context and returns a parsed map.
-func FetchControlInfo(ctx context.Context, ip string) (map[string]string, error) {
-	urlStr := fmt.Sprintf("http://%s/aircon/get_control_info", ip)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	if resp == nil {
-		return nil, fmt.Errorf("nil http response")
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		b, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("http %d: %s", resp.StatusCode, string(b))
-	}
-	body, err := io.ReadAll(resp.Body)
+// FetchControlInfo fetches control info using context and returns the decoded values.
+func (c *Client) FetchControlInfo(ctx context.Context, ip string) (*ControlInfo, error) {
+	values, err := c.get(ctx, ip, "aircon/get_control_info", "")
 	if err != nil {
 		return nil, err
 	}
-	pairs := strings.Split(string(body), ",")
-	parsedResponse := make(map[string]string)
-	for _, pair := range pairs {
-		kv := strings.Split(pair, "=")
-		if len(kv) == 2 {
-			parsedResponse[kv[0]] = kv[1]
-		}
+	info := newControlInfo(values)
+	if c.endpoint(ip).Dialect == DialectSkyfi {
+		info.Mode = skyfiModeFromWire(values["mode"])
+		info.FanRate = skyfiFanRateFromWire(values)
 	}
-	return parsedResponse, nil
+	return info, nil
 }
 
-// FetchBasicInfo fetches basic info using context and returns a parsed map.
-func FetchBasicInfo(ctx context.Context, ip string) (map[string]string, error) {
-	urlStr := fmt.Sprintf("http://%s/common/basic_info", ip)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil)
+// FetchBasicInfo fetches basic info using context and returns the decoded values.
+func (c *Client) FetchBasicInfo(ctx context.Context, ip string) (*BasicInfo, error) {
+	values, err := c.get(ctx, ip, "common/basic_info", "")
 	if err != nil {
 		return nil, err
 	}
-	resp, err := httpClient.Do(req)
+	return newBasicInfo(values), nil
+}
+
+// FetchSensorInfo fetches the room/outdoor sensor readings using context.
+func (c *Client) FetchSensorInfo(ctx context.Context, ip string) (*SensorInfo, error) {
+	values, err := c.get(ctx, ip, "aircon/get_sensor_info", "")
 	if err != nil {
 		return nil, err
 	}
-	if resp == nil {
-		return nil, fmt.Errorf("nil http response")
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		b, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("http %d: %s", resp.StatusCode, string(b))
-	}
-	body, err := io.ReadAll(resp.Body)
+	return newSensorInfo(values), nil
+}
+
+// FetchModelInfo fetches the model info used to derive the unit capabilities.
+func (c *Client) FetchModelInfo(ctx context.Context, ip string) (*ModelInfo, error) {
+	values, err := c.get(ctx, ip, "aircon/get_model_info", "")
 	if err != nil {
 		return nil, err
 	}
-	pairs := strings.Split(string(body), ",")
-	parsedResponse := make(map[string]string)
-	for _, pair := range pairs {
-		kv := strings.Split(pair, "=")
-		if len(kv) == 2 {
-			key, value := kv[0], kv[1]
-			if key == "name" || key == "grp_name" {
-				parsedResponse[key] = unquote(value)
-			} else {
-				parsedResponse[key] = value
-			}
-		}
-	}
-	return parsedResponse, nil
+	return newModelInfo(values), nil
 }
 
-// Legacy helpers (deprecated): retained for backward-compatibility
-func GetControlInfo(ip string) map[string]string {
-	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
-	defer cancel()
-	m, _ := FetchControlInfo(ctx, ip)
-	return m
+// ParseBasicInfo decodes a basic_info response body received outside of the client,
+// e.g. a UDP discovery reply
+func ParseBasicInfo(body string) (*BasicInfo, error) {
+	values := parseResponse(body)
+	if len(values) == 0 {
+		return nil, ErrMalformedResponse
+	}
+	if err := checkRet("common/basic_info", values); err != nil {
+		return nil, err
+	}
+	return newBasicInfo(values), nil
 }
 
-func GetBasicInfo(ip string) map[string]string {
-	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
-	defer cancel()
-	m, _ := FetchBasicInfo(ctx, ip)
-	return m
+// parseResponse decodes a comma-separated key=value adapter response
+func parseResponse(body string) map[string]string {
+	parsed := make(map[string]string)
+	for _, pair := range strings.Split(strings.TrimSpace(body), ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			continue
+		}
+		parsed[key] = unquote(value)
+	}
+	return parsed
 }
 
 // unquote is a simple function to decode URL-encoded strings.
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Capabilities describes the values a unit accepts for each control field
+type Capabilities struct {
+	Modes    []string `json:"modes"`
+	FanRates []string `json:"fan_rates"`
+	FanDirs  []string `json:"fan_dirs"`
+	TempMin  float64  `json:"temp_min"`
+	TempMax  float64  `json:"temp_max"`
+	Humidity bool     `json:"humidity"`
+	// SpecialModes lists the supported special modes (powerful, econo, streamer)
+	SpecialModes []string `json:"special_modes"`
+}
+
+// DefaultCapabilities returns the capability set assumed when a unit did not report its model info
+func DefaultCapabilities() Capabilities {
+	return Capabilities{
+		Modes:        []string{string(ModeAuto), string(ModeHeat), string(ModeDry), string(ModeFan), string(ModeCool)},
+		FanRates:     []string{string(FanRateAuto), string(FanRate1), string(FanRate2), string(FanRate3), string(FanRate4), string(FanRate5)},
+		FanDirs:      []string{string(FanDirStopped), string(FanDirVertical), string(FanDirHorizontal), string(FanDirBoth)},
+		TempMin:      16.0,
+		TempMax:      30.0,
+		SpecialModes: []string{string(SpecialPowerful), string(SpecialEcono), string(SpecialStreamer)},
+	}
+}
+
+// Capabilities derives the capability set from the model info flags.
+// Flags missing from the response keep the defaults.
+func (m ModelInfo) Capabilities() Capabilities {
+	caps := DefaultCapabilities()
+	v := m.Values
+
+	// type is "N" for heat pumps; cooling-only and heating-only units report "C" and "H"
+	switch v["type"] {
+	case "C":
+		caps.Modes = []string{string(ModeAuto), string(ModeDry), string(ModeFan), string(ModeCool)}
+	case "H":
+		caps.Modes = []string{string(ModeAuto), string(ModeHeat), string(ModeFan)}
+	}
+
+	switch v["en_frate"] {
+	case "0":
+		caps.FanRates = []string{string(FanRateAuto)}
+	case "1":
+		caps.FanRates = []string{string(FanRateAuto), string(FanRateQuiet), string(FanRate1), string(FanRate2), string(FanRate3), string(FanRate4), string(FanRate5)}
+	}
+
+	// s_fdir is a bitmask of the supported swing axes (1=vertical, 2=horizontal)
+	if v["en_fdir"] == "0" {
+		caps.FanDirs = []string{string(FanDirStopped)}
+	} else if sfdir, err := strconv.Atoi(v["s_fdir"]); err == nil {
+		dirs := []string{string(FanDirStopped)}
+		if sfdir&1 != 0 {
+			dirs = append(dirs, string(FanDirVertical))
+		}
+		if sfdir&2 != 0 {
+			dirs = append(dirs, string(FanDirHorizontal))
+		}
+		if sfdir&3 == 3 {
+			dirs = append(dirs, string(FanDirBoth))
+		}
+		caps.FanDirs = dirs
+	}
+
+	// temp_rng=1 marks units with the extended 10-32°C setpoint range
+	if v["temp_rng"] == "1" {
+		caps.TempMin = 10.0
+		caps.TempMax = 32.0
+	}
+
+	caps.Humidity = v["humd"] == "1" || (v["s_humd"] != "" && v["s_humd"] != "0")
+
+	// en_spmode is a bitmask of the supported special modes (1=powerful, 2=econo, 4=streamer)
+	if spmode, err := strconv.Atoi(v["en_spmode"]); err == nil {
+		caps.SpecialModes = []string{}
+		for bit, mode := range []SpecialMode{SpecialPowerful, SpecialEcono, SpecialStreamer} {
+			if spmode&(1<<bit) != 0 {
+				caps.SpecialModes = append(caps.SpecialModes, string(mode))
+			}
+		}
+	}
+
+	return caps
+}
+
+// Validate checks a Clim against the capability set
+func (c Capabilities) Validate(clim Clim) error {
+	// Validate power
+	if clim.Power != "0" && clim.Power != "1" {
+		return fmt.Errorf("power must be 0 or 1")
+	}
+
+	// Validate mode
+	if err := c.validateMode(clim.Mode); err != nil {
+		return err
+	}
+
+	// Validate temperature
+	if err := c.validateTemp(clim.Mode, clim.Temp); err != nil {
+		return err
+	}
+
+	// Validate fan rate
+	if !slices.Contains(c.FanRates, clim.FanRate) {
+		return fmt.Errorf("fan_rate must be one of %s", describeValues(c.FanRates, func(s string) string { return FanRate(s).String() }))
+	}
+
+	// Validate fan direction
+	if !slices.Contains(c.FanDirs, clim.FanDir) {
+		return fmt.Errorf("fan_dir must be one of %s", describeValues(c.FanDirs, func(s string) string { return FanDir(s).String() }))
+	}
+
+	return nil
+}
+
+// ValidateSetpoint checks a mode and temperature pair, e.g. of a weekly program entry,
+// against the capability set
+func (c Capabilities) ValidateSetpoint(mode, temp string) error {
+	if err := c.validateMode(mode); err != nil {
+		return err
+	}
+	return c.validateTemp(mode, temp)
+}
+
+func (c Capabilities) validateMode(mode string) error {
+	if !slices.Contains(c.Modes, mode) {
+		return fmt.Errorf("mode must be one of %s", describeValues(c.Modes, func(s string) string { return Mode(s).String() }))
+	}
+	return nil
+}
+
+// validateTemp checks the setpoint. DRY and FAN modes have no setpoint: the units report a
+// placeholder ("M" or "--") that is sent back unchanged, or the setpoint of the previous mode,
+// which they ignore. The other modes need a number within the supported range.
+func (c Capabilities) validateTemp(mode, temp string) error {
+	noSetpoint := mode == string(ModeDry) || mode == string(ModeFan)
+	if noSetpoint && isTempPlaceholder(temp) {
+		return nil
+	}
+	tempNum, err := strconv.ParseFloat(temp, 64)
+	if err != nil {
+		if noSetpoint {
+			return fmt.Errorf("temperature must be M, -- or a number in %s mode", Mode(mode))
+		}
+		return fmt.Errorf("temperature must be a number in %s mode, got %q", Mode(mode), temp)
+	}
+	if noSetpoint {
+		return nil
+	}
+	if tempNum < c.TempMin || tempNum > c.TempMax {
+		return fmt.Errorf("temperature must be between %.1f and %.1f", c.TempMin, c.TempMax)
+	}
+	return nil
+}
+
+// isTempPlaceholder reports whether stemp is the value reported in modes without a setpoint
+func isTempPlaceholder(stemp string) bool {
+	return stemp == "M" || stemp == "--"
+}
+
+// ValidateSpecialMode checks that the unit supports a special mode.
+// Capability sets stored before special modes were tracked (nil list) accept all modes.
+func (c Capabilities) ValidateSpecialMode(setting SpecialModeSetting) error {
+	if c.SpecialModes == nil {
+		return nil
+	}
+	if !slices.Contains(c.SpecialModes, string(setting.Mode)) {
+		if len(c.SpecialModes) == 0 {
+			return fmt.Errorf("special modes are not supported by this unit")
+		}
+		return fmt.Errorf("special mode must be one of %s", strings.Join(c.SpecialModes, ", "))
+	}
+	return nil
+}
+
+// describeValues formats allowed values as `0 (AUTO), 1 (HEAT)`
+func describeValues(values []string, name func(string) string) string {
+	parts := make([]string, 0, len(values))
+	for _, v := range values {
+		parts = append(parts, fmt.Sprintf("%s (%s)", v, name(v)))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,32 @@
+package api
+
+import "testing"
+
+func TestValidateSetpoint(t *testing.T) {
+	caps := DefaultCapabilities()
+	tests := []struct {
+		mode    Mode
+		temp    string
+		wantErr bool
+	}{
+		{ModeCool, "24", false},
+		{ModeCool, "24.5", false},
+		{ModeCool, "M", true},
+		{ModeCool, "--", true},
+		{ModeCool, "abc", true},
+		{ModeCool, "99", true},
+		{ModeHeat, "", true},
+		{ModeDry, "M", false},
+		{ModeDry, "--", false},
+		{ModeDry, "24", false},
+		{ModeDry, "abc", true},
+		{ModeFan, "--", false},
+		{ModeFan, "abc", true},
+	}
+	for _, tt := range tests {
+		err := caps.ValidateSetpoint(string(tt.mode), tt.temp)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateSetpoint(%s, %q) error = %v, want error %v", tt.mode, tt.temp, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CassetteFile is the name of the cassette inside the record/replay directory.
+// It holds one JSON-encoded Interaction per line.
+const CassetteFile = "cassette.jsonl"
+
+// redactedParams are query parameters replaced by "REDACTED" in cassettes (the adapter key)
+var redactedParams = []string{"key"}
+
+// redactedFields are response fields replaced by "REDACTED" in cassettes: wifi network
+// and credentials, cloud account, adapter key and terminal UUID
+var redactedFields = []string{"ssid", "key", "id", "pw", "uuid"}
+
+// pseudoMACPrefix starts the placeholders standing for the adapter MACs in cassettes
+// (locally administered, so they cannot clash with a real adapter)
+const pseudoMACPrefix = "0A52"
+
+// Interaction is one recorded request and its outcome
+type Interaction struct {
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	Status     int         `json:"status,omitempty"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	Error      string      `json:"error,omitempty"` // Transport error, replayed as is
+	RecordedAt time.Time   `json:"recorded_at"`
+}
+
+// Cassette holds the adapter traffic captured by Record and served back by Replay
+type Cassette struct {
+	path string
+
+	mu           sync.Mutex
+	Interactions []Interaction
+	served       map[string]int
+	macs         map[string]string // Real MAC -> placeholder, for this recording session
+	macBase      int               // Placeholders already used by the cassette when it was opened
+}
+
+// OpenCassette loads the cassette of dir, or returns an empty one when there is none yet.
+// The directory is created when missing.
+func OpenCassette(dir string) (*Cassette, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	c := &Cassette{path: filepath.Join(dir, CassetteFile), served: make(map[string]int)}
+	f, err := os.Open(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	for {
+		var in Interaction
+		if err := dec.Decode(&in); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse cassette %s: %w", c.path, err)
+		}
+		c.Interactions = append(c.Interactions, in)
+	}
+	used := make(map[string]bool)
+	for _, in := range c.Interactions {
+		if mac := parseResponse(in.Body)["mac"]; strings.HasPrefix(mac, pseudoMACPrefix) {
+			used[mac] = true
+		}
+	}
+	c.macBase = len(used)
+	return c, nil
+}
+
+// Path returns the cassette file path
+func (c *Cassette) Path() string {
+	return c.path
+}
+
+// Record returns a middleware appending every request and its response (or transport error)
+// to the cassette. Each interaction is appended to the file as it happens, so a crash keeps
+// what was captured.
+// Secrets are redacted from the recorded bodies (see redactedFields) and each adapter MAC is
+// replaced by a placeholder that stays the same for the whole recording session.
+func (c *Cassette) Record() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.RoundTrip(req)
+			in := Interaction{Method: req.Method, URL: redactURL(req.URL), RecordedAt: time.Now()}
+			if err != nil {
+				in.Error = err.Error()
+			} else {
+				body, rerr := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				if rerr != nil {
+					return nil, rerr
+				}
+				resp.Body = io.NopCloser(bytes.NewReader(body))
+				in.Status = resp.StatusCode
+				in.Header = resp.Header.Clone()
+				in.Body = c.redactBody(string(body))
+			}
+			if serr := c.append(in); serr != nil {
+				return nil, serr
+			}
+			return resp, err
+		})
+	}
+}
+
+// Replay returns a middleware answering from the cassette without reaching the network.
+// Requests are matched on method and URL; repeated requests get the recorded answers
+// in order, the last one being served again once they run out.
+func (c *Cassette) Replay() Middleware {
+	return func(http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			in, ok := c.next(req.Method, redactURL(req.URL))
+			if !ok {
+				return nil, fmt.Errorf("no recorded response for %s %s in %s", req.Method, redactURL(req.URL), c.path)
+			}
+			if in.Error != "" {
+				return nil, errors.New(in.Error)
+			}
+			return &http.Response{
+				Status:        fmt.Sprintf("%d %s", in.Status, http.StatusText(in.Status)),
+				StatusCode:    in.Status,
+				Proto:         "HTTP/1.1",
+				ProtoMajor:    1,
+				ProtoMinor:    1,
+				Header:        in.Header.Clone(),
+				Body:          io.NopCloser(bytes.NewReader([]byte(in.Body))),
+				ContentLength: int64(len(in.Body)),
+				Request:       req,
+			}, nil
+		})
+	}
+}
+
+// append adds in to the cassette and writes it as one line at the end of the file
+func (c *Cassette) append(in Interaction) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Interactions = append(c.Interactions, in)
+	f, err := os.OpenFile(c.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return f.Close()
+}
+
+// next returns the next recorded interaction for the request
+func (c *Cassette) next(method, u string) (Interaction, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := method + " " + u
+	var matches []Interaction
+	for _, in := range c.Interactions {
+		if in.Method+" "+in.URL == key {
+			matches = append(matches, in)
+		}
+	}
+	if len(matches) == 0 {
+		return Interaction{}, false
+	}
+	i := c.served[key]
+	if i >= len(matches) {
+		i = len(matches) - 1
+	}
+	c.served[key] = i + 1
+	return matches[i], true
+}
+
+// redactBody returns a key=value response body with the secret fields redacted and the MAC
+// replaced by its placeholder. Bodies that are not key=value lists are returned as is.
+func (c *Cassette) redactBody(body string) string {
+	pairs := strings.Split(body, ",")
+	for i, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || value == "" {
+			continue
+		}
+		switch {
+		case key == "mac":
+			pairs[i] = key + "=" + c.pseudoMAC(value)
+		case slices.Contains(redactedFields, key):
+			pairs[i] = key + "=REDACTED"
+		}
+	}
+	return strings.Join(pairs, ",")
+}
+
+// pseudoMAC returns the placeholder of mac, allocating the next one on first use
+func (c *Cassette) pseudoMAC(mac string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	mac = strings.ToUpper(mac)
+	if p, ok := c.macs[mac]; ok {
+		return p
+	}
+	if c.macs == nil {
+		c.macs = make(map[string]string)
+	}
+	p := fmt.Sprintf("%s%08X", pseudoMACPrefix, c.macBase+len(c.macs)+1)
+	c.macs[mac] = p
+	return p
+}
+
+// redactURL returns the URL with sorted query parameters and secrets redacted
+func redactURL(u *url.URL) string {
+	r := *u
+	q := r.Query()
+	for _, p := range redactedParams {
+		if q.Has(p) {
+			q.Set(p, "REDACTED")
+		}
+	}
+	r.RawQuery = q.Encode()
+	return r.String()
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCassetteRedactsSecrets(t *testing.T) {
+	host := adapterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ret=OK,type=aircon,name=Office,id=user@example.com,pw=secret,ssid=HomeWifi,mac=A0C9A0123456,led=1")
+	})
+	dir := t.TempDir()
+
+	cassette, err := OpenCassette(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(WithMiddleware(cassette.Record()))
+	for range 2 {
+		info, err := client.FetchBasicInfo(context.Background(), host)
+		if err != nil {
+			t.Fatalf("FetchBasicInfo: %v", err)
+		}
+		if info.MAC != "A0C9A0123456" {
+			t.Errorf("recording changed the response seen by the caller: mac=%s", info.MAC)
+		}
+	}
+
+	data, err := os.ReadFile(cassette.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "\n"); n != 2 {
+		t.Errorf("cassette has %d lines, want one per request", n)
+	}
+	for _, secret := range []string{"user@example.com", "secret", "HomeWifi", "A0C9A0123456"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains %q", secret)
+		}
+	}
+
+	replay, err := OpenCassette(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := NewClient(WithMiddleware(replay.Replay())).FetchBasicInfo(context.Background(), host)
+	if err != nil {
+		t.Fatalf("replayed FetchBasicInfo: %v", err)
+	}
+	if info.MAC != "0A5200000001" || info.Name != "Office" || info.Values["pw"] != "REDACTED" {
+		t.Errorf("replayed basic info = %v", info.Values)
+	}
+}
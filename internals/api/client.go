@@ -0,0 +1,255 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds a whole request (dial, headers and body) to an adapter
+const DefaultTimeout = 4 * time.Second
+
+// Middleware wraps the transport of a Client, e.g. to log, record or rewrite requests.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to http.RoundTripper.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req).
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Endpoint holds the per-adapter transport settings that differ from the client defaults
+type Endpoint struct {
+	Scheme  string  // "http" or "https"; empty means the client scheme
+	UUID    string  // Terminal UUID sent as X-Daikin-uuid (HTTPS adapters, after register_terminal)
+	Dialect Dialect // API flavour; empty means DialectStandard
+}
+
+// Client talks to the adapters' HTTP API.
+// A zero Client is not usable; create one with NewClient.
+type Client struct {
+	httpClient *http.Client
+	scheme     string
+	userAgent  string
+	mu         sync.RWMutex
+	endpoints  map[string]Endpoint
+	retry      RetryPolicy
+	budget     *retryBudget
+	queue      *hostQueue
+}
+
+// Option configures a Client.
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	transport  http.RoundTripper
+	timeout    time.Duration
+	scheme     string
+	userAgent  string
+	middleware []Middleware
+	endpoints  map[string]Endpoint
+	retry      RetryPolicy
+}
+
+// WithTransport replaces the default transport.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(o *clientOptions) { o.transport = rt }
+}
+
+// WithTimeout sets the per-request timeout (DefaultTimeout when not set).
+func WithTimeout(d time.Duration) Option {
+	return func(o *clientOptions) { o.timeout = d }
+}
+
+// WithScheme sets the URL scheme used to reach the adapters ("http" when not set).
+func WithScheme(scheme string) Option {
+	return func(o *clientOptions) { o.scheme = scheme }
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(ua string) Option {
+	return func(o *clientOptions) { o.userAgent = ua }
+}
+
+// WithEndpoints sets per-adapter transport settings, keyed by the adapter address.
+// It may be given several times; later entries win.
+func WithEndpoints(endpoints map[string]Endpoint) Option {
+	return func(o *clientOptions) {
+		if o.endpoints == nil {
+			o.endpoints = make(map[string]Endpoint, len(endpoints))
+		}
+		for ip, ep := range endpoints {
+			o.endpoints[ip] = ep
+		}
+	}
+}
+
+// WithMiddleware wraps the transport. The first middleware is the outermost one.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(o *clientOptions) { o.middleware = append(o.middleware, mw...) }
+}
+
+// NewClient creates a Client with the given options.
+func NewClient(opts ...Option) *Client {
+	o := clientOptions{
+		timeout: DefaultTimeout,
+		scheme:  "http",
+		retry:   DefaultRetryPolicy(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	transport := o.transport
+	if transport == nil {
+		transport = defaultTransport()
+	}
+	for i := len(o.middleware) - 1; i >= 0; i-- {
+		transport = o.middleware[i](transport)
+	}
+
+	c := &Client{
+		httpClient: &http.Client{Timeout: o.timeout, Transport: transport},
+		scheme:     o.scheme,
+		userAgent:  o.userAgent,
+		retry:      o.retry,
+		budget:     newRetryBudget(o.retry.Budget),
+		queue:      newHostQueue(),
+	}
+	c.endpoints = make(map[string]Endpoint, len(o.endpoints))
+	for ip, ep := range o.endpoints {
+		c.endpoints[ip] = ep
+	}
+	return c
+}
+
+// endpoint returns the transport settings of ip
+func (c *Client) endpoint(ip string) Endpoint {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.endpoints[ip]
+}
+
+// setDialect records the dialect of ip for the following requests
+func (c *Client) setDialect(ip string, dialect Dialect) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ep := c.endpoints[ip]
+	ep.Dialect = dialect
+	c.endpoints[ip] = ep
+}
+
+// adapterTLSKey marks the context of requests to an endpoint registered as an HTTPS adapter
+type adapterTLSKey struct{}
+
+// defaultTransport returns a transport tuned for small LAN devices.
+// HTTPS adapters ship a self-signed certificate that cannot be verified, so verification is
+// skipped for the requests to endpoints registered with the https scheme, and only for those.
+func defaultTransport() http.RoundTripper {
+	verified := lanTransport(nil)
+	adapters := lanTransport(&tls.Config{InsecureSkipVerify: true})
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Context().Value(adapterTLSKey{}) != nil {
+			return adapters.RoundTrip(req)
+		}
+		return verified.RoundTrip(req)
+	})
+}
+
+// lanTransport returns an http.Transport with short timeouts and the given TLS configuration
+func lanTransport(tlsConfig *tls.Config) *http.Transport {
+	return &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         (&net.Dialer{Timeout: 3 * time.Second, KeepAlive: 30 * time.Second}).DialContext,
+		TLSClientConfig:     tlsConfig,
+		TLSHandshakeTimeout: 3 * time.Second,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+		MaxIdleConnsPerHost: 10,
+	}
+}
+
+// get performs a GET on the adapter endpoint and returns the parsed key/value response.
+// Requests to the same adapter are serialized, and reads are retried according to the retry policy.
+func (c *Client) get(ctx context.Context, ip, path, rawQuery string) (map[string]string, error) {
+	return c.send(ctx, ip, c.endpoint(ip), path, rawQuery)
+}
+
+// send is get with explicit transport settings
+func (c *Client) send(ctx context.Context, ip string, ep Endpoint, path, rawQuery string) (map[string]string, error) {
+	attempts := 1
+	if isIdempotent(path) && c.retry.MaxAttempts > 1 {
+		attempts = c.retry.MaxAttempts
+	}
+	budget := retryBudgetFrom(ctx, c.budget)
+
+	for attempt := 1; ; attempt++ {
+		release, err := c.queue.acquire(ctx, ip)
+		if err != nil {
+			return nil, err
+		}
+		values, err := c.do(ctx, ip, ep, path, rawQuery)
+		release()
+
+		if attempt >= attempts || !isRetryable(ctx, err) || !budget.take() {
+			return values, err
+		}
+		if err := sleep(ctx, c.retry.backoff(attempt)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// do performs a single GET attempt
+func (c *Client) do(ctx context.Context, ip string, ep Endpoint, path, rawQuery string) (map[string]string, error) {
+	scheme := c.scheme
+	if ep.Scheme != "" {
+		scheme = ep.Scheme
+	}
+	if ep.Scheme == "https" {
+		ctx = context.WithValue(ctx, adapterTLSKey{}, true)
+	}
+	u := &url.URL{Scheme: scheme, Host: ip, Path: ep.Dialect.path(path), RawQuery: rawQuery}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if ep.UUID != "" {
+		req.Header.Set("X-Daikin-uuid", ep.UUID)
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("nil http response")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(resp.Body)
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(b)}
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	values := parseResponse(string(body))
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%s: %w", path, ErrMalformedResponse)
+	}
+	if err := checkRet(path, values); err != nil {
+		return values, err
+	}
+	return values, nil
+}
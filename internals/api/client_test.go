@@ -0,0 +1,221 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// adapterServer starts an httptest server standing in for an adapter and returns its host:port
+func adapterServer(t *testing.T, handler http.HandlerFunc) string {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Host
+}
+
+func TestClientGetParsesResponse(t *testing.T) {
+	host := adapterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/common/basic_info" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "ret=OK,type=aircon,mac=0A5100000001,name=%4f%66%66%69%63%65,grp_name=Floor%201,led=1")
+	})
+
+	info, err := NewClient().FetchBasicInfo(context.Background(), host)
+	if err != nil {
+		t.Fatalf("FetchBasicInfo: %v", err)
+	}
+	if info.Name != "Office" || info.GroupName != "Floor 1" {
+		t.Errorf("name/group = %q/%q, want Office/Floor 1", info.Name, info.GroupName)
+	}
+	if got := info.HardwareAddr(); got != "0a:51:00:00:00:01" {
+		t.Errorf("HardwareAddr = %q", got)
+	}
+	if !info.LED {
+		t.Error("LED = false, want true")
+	}
+}
+
+func TestClientRetErrors(t *testing.T) {
+	tests := []struct {
+		ret  string
+		want error
+	}{
+		{"PARAM NG", ErrParamNG},
+		{"ADV_NG", ErrAdvNG},
+		{"SERIAL NG", ErrSerialNG},
+		{"NG", ErrNG},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ret, func(t *testing.T) {
+			var calls atomic.Int32
+			host := adapterServer(t, func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				fmt.Fprintf(w, "ret=%s", tt.ret)
+			})
+
+			err := NewClient().SetClim(context.Background(), Clim{IP: host, Power: "1", Mode: "3", Temp: "24"})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("SetClim error = %v, want %v", err, tt.want)
+			}
+			var retErr *RetError
+			if !errors.As(err, &retErr) || retErr.Path != "aircon/set_control_info" {
+				t.Errorf("error does not carry the endpoint path: %v", err)
+			}
+			if n := calls.Load(); n != 1 {
+				t.Errorf("write sent %d times, want 1", n)
+			}
+		})
+	}
+}
+
+func TestClientRetriesMalformedReads(t *testing.T) {
+	var calls atomic.Int32
+	host := adapterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			fmt.Fprint(w, "garbage")
+			return
+		}
+		fmt.Fprint(w, "ret=OK,pow=1,mode=4,stemp=24,f_rate=A,f_dir=0")
+	})
+
+	client := NewClient(WithRetry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}))
+	info, err := client.FetchControlInfo(context.Background(), host)
+	if err != nil {
+		t.Fatalf("FetchControlInfo: %v", err)
+	}
+	if info.Temp != "24" || info.Mode != ModeCool {
+		t.Errorf("control info = %+v", info)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("requests = %d, want 2", n)
+	}
+}
+
+func TestClientMiddlewareOrder(t *testing.T) {
+	host := adapterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "ret=OK,trace=%s", r.Header.Get("X-Trace"))
+	})
+
+	var order []string
+	tag := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				req.Header.Set("X-Trace", strings.TrimPrefix(req.Header.Get("X-Trace")+"/"+name, "/"))
+				return next.RoundTrip(req)
+			})
+		}
+	}
+
+	client := NewClient(WithMiddleware(tag("outer"), tag("inner")))
+	values, err := client.get(context.Background(), host, "common/basic_info", "")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got := strings.Join(order, ","); got != "outer,inner" {
+		t.Errorf("middleware order = %s, want outer,inner", got)
+	}
+	if values["trace"] != "outer/inner" {
+		t.Errorf("trace = %q, want outer/inner", values["trace"])
+	}
+}
+
+func TestLegacyHelpers(t *testing.T) {
+	host := adapterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ret=OK,pow=0,mode=4,stemp=21")
+	})
+
+	values := GetControlInfo(host)
+	if values["mode"] != "4" || values["stemp"] != "21" {
+		t.Errorf("GetControlInfo = %v", values)
+	}
+}
+
+func TestRetryBudgetPerOperation(t *testing.T) {
+	var calls atomic.Int32
+	host := adapterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		fmt.Fprint(w, "garbage")
+	})
+
+	client := NewClient(WithRetry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, Budget: 2}))
+	fetch := func(ctx context.Context) int32 {
+		calls.Store(0)
+		if _, err := client.FetchControlInfo(ctx, host); !errors.Is(err, ErrMalformedResponse) {
+			t.Fatalf("FetchControlInfo error = %v, want ErrMalformedResponse", err)
+		}
+		return calls.Load()
+	}
+
+	// The client budget is spent by the first request
+	if n := fetch(context.Background()); n != 3 {
+		t.Errorf("first request: %d attempts, want 3", n)
+	}
+	if n := fetch(context.Background()); n != 1 {
+		t.Errorf("after the client budget is spent: %d attempts, want 1", n)
+	}
+	// A new operation starts with a full budget
+	if n := fetch(client.WithRetryBudget(context.Background())); n != 3 {
+		t.Errorf("new operation: %d attempts, want 3", n)
+	}
+}
+
+func TestClientHTTPSAdapter(t *testing.T) {
+	const uuid = "0123456789abcdef0123456789abcdef"
+	var registered atomic.Bool
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/common/register_terminal":
+			if r.Header.Get("X-Daikin-uuid") != uuid || r.URL.Query().Get("key") != "0123456789012" {
+				fmt.Fprint(w, "ret=PARAM NG")
+				return
+			}
+			registered.Store(true)
+			fmt.Fprint(w, "ret=OK")
+		case !registered.Load() || r.Header.Get("X-Daikin-uuid") != uuid:
+			http.Error(w, "forbidden", http.StatusForbidden)
+		default:
+			fmt.Fprint(w, "ret=OK,type=aircon,name=Secure")
+		}
+	}))
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host := u.Host
+
+	client := NewClient(WithEndpoints(map[string]Endpoint{host: {Scheme: "https", UUID: uuid}}))
+	if err := client.RegisterTerminal(context.Background(), host, "0123456789012"); err != nil {
+		t.Fatalf("RegisterTerminal: %v", err)
+	}
+	info, err := client.FetchBasicInfo(context.Background(), host)
+	if err != nil {
+		t.Fatalf("FetchBasicInfo: %v", err)
+	}
+	if info.Name != "Secure" {
+		t.Errorf("name = %q, want Secure", info.Name)
+	}
+
+	// Hosts not registered as HTTPS adapters keep certificate verification
+	_, err = NewClient(WithScheme("https")).FetchBasicInfo(context.Background(), host)
+	var certErr *tls.CertificateVerificationError
+	if !errors.As(err, &certErr) {
+		t.Errorf("unregistered host error = %v, want a certificate verification error", err)
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// DemandModeManual is the get_demand_control "mode" of a fixed power cap
+const DemandModeManual = "0"
+
+// demandDayKeys maps the Weekdays to the per-day schedule fields of get_demand_control
+var demandDayKeys = map[string]string{
+	"mon": "moc", "tue": "tuc", "wed": "wec", "thu": "thc", "fri": "frc", "sat": "sac", "sun": "suc",
+}
+
+// DemandControl is the power cap configured on the adapter, as returned by
+// get_demand_control: type, en_demand, mode, max_pow, scdl_per_day and one
+// schedule field per day (moc, tuc, wec, thc, frc, sac, suc).
+// The per-day fields are kept as sent by the adapter and written back unchanged.
+//
+// Experimental, see ExperimentalNote.
+type DemandControl struct {
+	Type            string
+	Enabled         bool
+	Mode            string            // DemandModeManual for a fixed cap
+	MaxPower        int               // Percentage of the rated power
+	SchedulesPerDay string            // scdl_per_day
+	Days            map[string]string // Per-day schedule fields keyed by Weekdays
+	Values          map[string]string
+}
+
+// FetchDemandControl fetches the demand control settings using context.
+func (c *Client) FetchDemandControl(ctx context.Context, ip string) (*DemandControl, error) {
+	values, err := c.get(ctx, ip, "aircon/get_demand_control", "")
+	if err != nil {
+		return nil, err
+	}
+	maxPow, _ := strconv.Atoi(values["max_pow"])
+	demand := &DemandControl{
+		Type:            values["type"],
+		Enabled:         values["en_demand"] == "1",
+		Mode:            values["mode"],
+		MaxPower:        maxPow,
+		SchedulesPerDay: values["scdl_per_day"],
+		Days:            map[string]string{},
+		Values:          values,
+	}
+	for _, day := range Weekdays {
+		if v, ok := values[demandDayKeys[day]]; ok {
+			demand.Days[day] = v
+		}
+	}
+	return demand, nil
+}
+
+// SetDemandControl replaces the demand control settings using context.
+// type, scdl_per_day and the per-day fields are only sent when set, so that a
+// DemandControl returned by FetchDemandControl is written back with the fields the
+// adapter reported. Experimental, see ExperimentalNote.
+func (c *Client) SetDemandControl(ctx context.Context, ip string, demand DemandControl) error {
+	mode := demand.Mode
+	if mode == "" {
+		mode = DemandModeManual
+	}
+	query := ""
+	if demand.Type != "" {
+		query = "type=" + url.QueryEscape(demand.Type) + "&"
+	}
+	query += fmt.Sprintf("en_demand=%s&mode=%s&max_pow=%d", boolParam(demand.Enabled), url.QueryEscape(mode), demand.MaxPower)
+	if demand.SchedulesPerDay != "" {
+		query += "&scdl_per_day=" + url.QueryEscape(demand.SchedulesPerDay)
+	}
+	for _, day := range Weekdays {
+		if v, ok := demand.Days[day]; ok {
+			query += "&" + demandDayKeys[day] + "=" + url.QueryEscape(v)
+		}
+	}
+	_, err := c.get(ctx, ip, "aircon/set_demand_control", query)
+	return err
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestSkyfiTranslatesModesAndZones(t *testing.T) {
+	var query string
+	host := adapterServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/skyfi/aircon/get_control_info":
+			fmt.Fprint(w, "ret=OK,pow=1,mode=7,stemp=M,f_rate=3,f_auto=0,f_dir=0")
+		default:
+			query = r.URL.RawQuery
+			fmt.Fprint(w, "ret=OK")
+		}
+	})
+	client := NewClient(WithEndpoints(map[string]Endpoint{host: {Dialect: DialectSkyfi}}))
+	ctx := context.Background()
+
+	info, err := client.FetchControlInfo(ctx, host)
+	if err != nil {
+		t.Fatalf("FetchControlInfo: %v", err)
+	}
+	if info.Mode != ModeDry || info.FanRate != FanRate3 {
+		t.Errorf("mode/fan rate = %s/%s, want %s/%s", info.Mode, info.FanRate, ModeDry, FanRate3)
+	}
+
+	if err := client.SetClim(ctx, Clim{IP: host, Power: "1", Mode: string(ModeCool), Temp: "24", FanRate: "A"}); err != nil {
+		t.Fatalf("SetClim: %v", err)
+	}
+	if want := "pow=1&stemp=24&mode=2&shum=&f_rate=1&f_dir=&f_auto=1&lpw="; query != want {
+		t.Errorf("set_control_info query = %s, want %s", query, want)
+	}
+
+	zones := Zones{Names: []string{"Kids & Guest", "Hall=1", "A+B"}, On: []bool{true, false, true}}
+	if err := client.SetZones(ctx, host, zones); err != nil {
+		t.Fatalf("SetZones: %v", err)
+	}
+	if want := "zone_name=Kids+%26+Guest;Hall%3D1;A%2BB&zone_onoff=1;0;1"; query != want {
+		t.Errorf("set_zone_setting query = %s, want %s", query, want)
+	}
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// DayPower is the decoded response of /aircon/get_day_power_ex.
+// Hourly values are reported by the adapter in 0.1 kWh and converted to kWh.
+type DayPower struct {
+	HeatHourly []float64 // curr_day_heat, hour 0 to 23
+	CoolHourly []float64 // curr_day_cool, hour 0 to 23
+	Values     map[string]string
+}
+
+// TotalKWh returns today's heating plus cooling consumption in kWh
+func (d DayPower) TotalKWh() float64 {
+	return sum(d.HeatHourly) + sum(d.CoolHourly)
+}
+
+// WeekPower is the decoded response of /aircon/get_week_power.
+// Daily values are reported by the adapter in Wh and converted to kWh.
+type WeekPower struct {
+	TodayRuntime int       // today_runtime in minutes
+	Daily        []float64 // datas, oldest day first, today last
+	Values       map[string]string
+}
+
+// TotalKWh returns the consumption of the last seven days in kWh
+func (w WeekPower) TotalKWh() float64 {
+	return sum(w.Daily)
+}
+
+// YearPower is the decoded response of /aircon/get_year_power.
+// Monthly values are reported by the adapter in kWh.
+type YearPower struct {
+	ThisYear     []float64 // this_year, January first
+	PreviousYear []float64 // previous_year, January first
+	Values       map[string]string
+}
+
+// TotalKWh returns this year's consumption in kWh
+func (y YearPower) TotalKWh() float64 {
+	return sum(y.ThisYear)
+}
+
+// FetchDayPower fetches today's hourly consumption using context.
+func (c *Client) FetchDayPower(ctx context.Context, ip string) (*DayPower, error) {
+	values, err := c.get(ctx, ip, "aircon/get_day_power_ex", "")
+	if err != nil {
+		return nil, err
+	}
+	return &DayPower{
+		HeatHourly: parseSeries(values["curr_day_heat"], 0.1),
+		CoolHourly: parseSeries(values["curr_day_cool"], 0.1),
+		Values:     values,
+	}, nil
+}
+
+// FetchWeekPower fetches the daily consumption of the last seven days using context.
+func (c *Client) FetchWeekPower(ctx context.Context, ip string) (*WeekPower, error) {
+	values, err := c.get(ctx, ip, "aircon/get_week_power", "")
+	if err != nil {
+		return nil, err
+	}
+	runtime, _ := strconv.Atoi(values["today_runtime"])
+	return &WeekPower{
+		TodayRuntime: runtime,
+		Daily:        parseSeries(values["datas"], 0.001),
+		Values:       values,
+	}, nil
+}
+
+// FetchYearPower fetches the monthly consumption of this and the previous year using context.
+func (c *Client) FetchYearPower(ctx context.Context, ip string) (*YearPower, error) {
+	values, err := c.get(ctx, ip, "aircon/get_year_power", "")
+	if err != nil {
+		return nil, err
+	}
+	return &YearPower{
+		ThisYear:     parseSeries(values["this_year"], 1),
+		PreviousYear: parseSeries(values["previous_year"], 1),
+		Values:       values,
+	}, nil
+}
+
+// parseSeries parses a "/"-separated list of numbers, scaling each value.
+// Unparseable entries count as zero so that indexes keep their meaning.
+func parseSeries(s string, scale float64) []float64 {
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(s, "/")
+	series := make([]float64, len(parts))
+	for i, p := range parts {
+		if v, err := strconv.ParseFloat(p, 64); err == nil {
+			series[i] = v * scale
+		}
+	}
+	return series
+}
+
+func sum(values []float64) float64 {
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
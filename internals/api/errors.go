@@ -0,0 +1,73 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+)
+
+// RetError reports a non-OK "ret=" status returned by an adapter
+type RetError struct {
+	Path string // Endpoint that returned the status
+	Code string // Raw ret value, e.g. "PARAM NG"
+}
+
+// Sentinel errors for the ret codes known to be returned by the adapters.
+// Match them with errors.Is; the returned error carries the endpoint path.
+var (
+	ErrParamNG  = &RetError{Code: "PARAM NG"}
+	ErrAdvNG    = &RetError{Code: "ADV_NG"}
+	ErrSerialNG = &RetError{Code: "SERIAL NG"}
+	ErrNG       = &RetError{Code: "NG"}
+)
+
+func (e *RetError) Error() string {
+	reason := ""
+	switch e.Code {
+	case ErrParamNG.Code:
+		reason = "invalid or unsupported parameter"
+	case ErrAdvNG.Code:
+		reason = "not allowed in the current mode"
+	case ErrSerialNG.Code:
+		reason = "adapter serial communication failed"
+	case ErrNG.Code:
+		reason = "request refused"
+	}
+	msg := fmt.Sprintf("ret=%s", e.Code)
+	if e.Path != "" {
+		msg = fmt.Sprintf("%s: %s", e.Path, msg)
+	}
+	if reason != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, reason)
+	}
+	return msg
+}
+
+// Is reports whether target is a RetError with the same code
+func (e *RetError) Is(target error) bool {
+	t, ok := target.(*RetError)
+	return ok && t.Code == e.Code
+}
+
+// ErrMalformedResponse is returned when an adapter answers with a body that holds no key=value pair.
+// Adapters under load sometimes send truncated or empty bodies; reads are retried on it.
+var ErrMalformedResponse = errors.New("malformed adapter response")
+
+// StatusError reports a non-2xx HTTP status returned by an adapter
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("http %d: %s", e.StatusCode, e.Body)
+}
+
+// checkRet returns a RetError when the parsed response carries a non-OK ret value.
+// Responses without a ret key are accepted for older firmware.
+func checkRet(path string, values map[string]string) error {
+	ret, ok := values["ret"]
+	if !ok || ret == "OK" {
+		return nil
+	}
+	return &RetError{Path: path, Code: ret}
+}
@@ -0,0 +1,8 @@
+package api
+
+// ExperimentalNote explains why writing the weekly program and the demand control is experimental.
+// The doc comments, the command help and the --experimental check all refer to it.
+const ExperimentalNote = `The weekly program (get/set_program) and demand control (get/set_demand_control)
+endpoints are not part of any published adapter API documentation and have only been
+checked against some firmwares. Read the current settings of a device before writing
+new ones, and read them again afterwards.`
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SetName changes the device name reported in basic_info using context.
+func (c *Client) SetName(ctx context.Context, ip, name string) error {
+	_, err := c.get(ctx, ip, "common/set_name", "name="+encodeName(name))
+	return err
+}
+
+// SetGroupName changes the group name (grp_name) reported in basic_info using context.
+func (c *Client) SetGroupName(ctx context.Context, ip, group string) error {
+	_, err := c.get(ctx, ip, "common/set_grp_name", "en_grp=1&grp_name="+encodeName(group))
+	return err
+}
+
+// encodeName percent-encodes every byte, the way the adapters report names in basic_info
+func encodeName(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		fmt.Fprintf(&b, "%%%02x", s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"sync"
+)
+
+// Package-level helpers kept from before the Client type so that existing callers keep building.
+// They use a shared Client with the default options.
+
+var (
+	defaultClientOnce sync.Once
+	defaultClient     *Client
+)
+
+// legacyClient returns the Client shared by the package-level helpers
+func legacyClient() *Client {
+	defaultClientOnce.Do(func() { defaultClient = NewClient() })
+	return defaultClient
+}
+
+// SetClim performs a control update with the default client.
+//
+// Deprecated: use NewClient and Client.SetClim.
+func SetClim(ctx context.Context, clim Clim) error {
+	return legacyClient().SetClim(ctx, clim)
+}
+
+// FetchControlInfo fetches control info with the default client and returns the raw values.
+//
+// Deprecated: use NewClient and Client.FetchControlInfo.
+func FetchControlInfo(ctx context.Context, ip string) (map[string]string, error) {
+	info, err := legacyClient().FetchControlInfo(ctx, ip)
+	if err != nil {
+		return nil, err
+	}
+	return info.Values, nil
+}
+
+// FetchBasicInfo fetches basic info with the default client and returns the raw values.
+//
+// Deprecated: use NewClient and Client.FetchBasicInfo.
+func FetchBasicInfo(ctx context.Context, ip string) (map[string]string, error) {
+	info, err := legacyClient().FetchBasicInfo(ctx, ip)
+	if err != nil {
+		return nil, err
+	}
+	return info.Values, nil
+}
+
+// GetControlInfo fetches control info and returns nil on failure.
+//
+// Deprecated: use NewClient and Client.FetchControlInfo.
+func GetControlInfo(ip string) map[string]string {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	m, _ := FetchControlInfo(ctx, ip)
+	return m
+}
+
+// GetBasicInfo fetches basic info and returns nil on failure.
+//
+// Deprecated: use NewClient and Client.FetchBasicInfo.
+func GetBasicInfo(ip string) map[string]string {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+	defer cancel()
+	m, _ := FetchBasicInfo(ctx, ip)
+	return m
+}
@@ -1,5 +1,7 @@
 package api
 
+import "strings"
+
 type Clim struct {
 	IP      string
 	Power   string
@@ -9,3 +11,223 @@ type Clim struct {
 	FanRate string
 	FanDir  string
 }
+
+// Mode is the operating mode reported in control_info "mode"
+type Mode string
+
+const (
+	ModeAuto Mode = "0"
+	ModeHeat Mode = "1"
+	ModeDry  Mode = "2"
+	ModeFan  Mode = "3"
+	ModeCool Mode = "4"
+)
+
+// String returns the display name of the mode, or the raw value if unknown
+func (m Mode) String() string {
+	switch m {
+	case ModeAuto:
+		return "AUTO"
+	case ModeHeat:
+		return "HEAT"
+	case ModeDry:
+		return "DRY"
+	case ModeFan:
+		return "FAN"
+	case ModeCool:
+		return "COOL"
+	}
+	return string(m)
+}
+
+// FanRate is the fan speed reported in control_info "f_rate"
+type FanRate string
+
+const (
+	FanRateAuto  FanRate = "A"
+	FanRateQuiet FanRate = "B"
+	FanRate1     FanRate = "3"
+	FanRate2     FanRate = "4"
+	FanRate3     FanRate = "5"
+	FanRate4     FanRate = "6"
+	FanRate5     FanRate = "7"
+)
+
+// String returns the display name of the fan rate, or the raw value if unknown
+func (f FanRate) String() string {
+	switch f {
+	case FanRateAuto:
+		return "Auto"
+	case FanRateQuiet:
+		return "Quiet"
+	case FanRate1:
+		return "Level 1"
+	case FanRate2:
+		return "Level 2"
+	case FanRate3:
+		return "Level 3"
+	case FanRate4:
+		return "Level 4"
+	case FanRate5:
+		return "Level 5"
+	}
+	return string(f)
+}
+
+// FanDir is the wing motion reported in control_info "f_dir"
+type FanDir string
+
+const (
+	FanDirStopped    FanDir = "0"
+	FanDirVertical   FanDir = "1"
+	FanDirHorizontal FanDir = "2"
+	FanDirBoth       FanDir = "3"
+)
+
+// String returns the display name of the fan direction, or the raw value if unknown
+func (f FanDir) String() string {
+	switch f {
+	case FanDirStopped:
+		return "All wings stopped"
+	case FanDirVertical:
+		return "Vertical wings motion"
+	case FanDirHorizontal:
+		return "Horizontal wings motion"
+	case FanDirBoth:
+		return "Vertical and horizontal wings motion"
+	}
+	return string(f)
+}
+
+// ControlInfo is the decoded response of /aircon/get_control_info
+type ControlInfo struct {
+	Power    bool
+	Mode     Mode
+	Temp     string // stemp; may be "M" or "--" in modes without a setpoint
+	Humidity string // shum
+	FanRate  FanRate
+	FanDir   FanDir
+	Advanced string // adv; active special modes separated by "/"
+	Values   map[string]string
+}
+
+// Clim returns the control info as a Clim ready to be sent back to the device
+func (c ControlInfo) Clim(ip string) Clim {
+	power := "0"
+	if c.Power {
+		power = "1"
+	}
+	return Clim{
+		IP:      ip,
+		Power:   power,
+		Mode:    string(c.Mode),
+		Temp:    c.Temp,
+		Shum:    c.Humidity,
+		FanRate: string(c.FanRate),
+		FanDir:  string(c.FanDir),
+	}
+}
+
+// BasicInfo is the decoded response of /common/basic_info
+type BasicInfo struct {
+	Type      string
+	Region    string
+	Version   string
+	Revision  string
+	Power     bool
+	Error     string
+	Name      string
+	Icon      string
+	Method    string
+	Port      string
+	ID        string
+	LED       bool
+	MAC       string
+	AdpKind   string
+	GroupName string
+	Values    map[string]string
+}
+
+func newControlInfo(values map[string]string) *ControlInfo {
+	return &ControlInfo{
+		Power:    values["pow"] == "1",
+		Mode:     Mode(values["mode"]),
+		Temp:     values["stemp"],
+		Humidity: values["shum"],
+		FanRate:  FanRate(values["f_rate"]),
+		FanDir:   FanDir(values["f_dir"]),
+		Advanced: values["adv"],
+		Values:   values,
+	}
+}
+
+// HardwareAddr returns the MAC address formatted like the discovery results ("aa:bb:cc:dd:ee:ff").
+// basic_info reports it as 12 hex digits without separators.
+func (b BasicInfo) HardwareAddr() string {
+	mac := strings.ToLower(b.MAC)
+	if len(mac) != 12 {
+		return mac
+	}
+	parts := make([]string, 0, 6)
+	for i := 0; i < 12; i += 2 {
+		parts = append(parts, mac[i:i+2])
+	}
+	return strings.Join(parts, ":")
+}
+
+func newBasicInfo(values map[string]string) *BasicInfo {
+	return &BasicInfo{
+		Type:      values["type"],
+		Region:    values["reg"],
+		Version:   values["ver"],
+		Revision:  values["rev"],
+		Power:     values["pow"] == "1",
+		Error:     values["err"],
+		Name:      values["name"],
+		Icon:      values["icon"],
+		Method:    values["method"],
+		Port:      values["port"],
+		ID:        values["id"],
+		LED:       values["led"] == "1",
+		MAC:       values["mac"],
+		AdpKind:   values["adp_kind"],
+		GroupName: values["grp_name"],
+		Values:    values,
+	}
+}
+
+// SensorInfo is the decoded response of /aircon/get_sensor_info
+type SensorInfo struct {
+	RoomTemp       string // htemp in °C
+	RoomHumidity   string // hhum in %, "-" when the unit has no sensor
+	OutdoorTemp    string // otemp in °C, "-" when unavailable
+	Error          string // err
+	CompressorFreq string // cmpfreq in Hz
+	Values         map[string]string
+}
+
+func newSensorInfo(values map[string]string) *SensorInfo {
+	return &SensorInfo{
+		RoomTemp:       values["htemp"],
+		RoomHumidity:   values["hhum"],
+		OutdoorTemp:    values["otemp"],
+		Error:          values["err"],
+		CompressorFreq: values["cmpfreq"],
+		Values:         values,
+	}
+}
+
+// ModelInfo is the decoded response of /aircon/get_model_info
+type ModelInfo struct {
+	Model  string
+	Type   string
+	Values map[string]string
+}
+
+func newModelInfo(values map[string]string) *ModelInfo {
+	return &ModelInfo{
+		Model:  values["model"],
+		Type:   values["type"],
+		Values: values,
+	}
+}
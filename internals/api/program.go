@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Weekdays lists the program day keys in adapter order
+var Weekdays = []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+
+// ProgramEntry is a single switch point of the weekly program
+type ProgramEntry struct {
+	Time  string // "HHMM"
+	Power string // "0" or "1"
+	Mode  string // Empty when the entry switches the unit off
+	Temp  string // Empty when the entry switches the unit off
+}
+
+// Program is the weekly program stored on the adapter.
+// Each day is encoded as "/"-separated entries of the form HHMM-pow-mode-stemp.
+//
+// Experimental, see ExperimentalNote.
+type Program struct {
+	Enabled bool
+	Days    map[string][]ProgramEntry // Keyed by Weekdays
+	Values  map[string]string
+}
+
+// FetchProgram fetches the weekly program using context.
+func (c *Client) FetchProgram(ctx context.Context, ip string) (*Program, error) {
+	values, err := c.get(ctx, ip, "aircon/get_program", "")
+	if err != nil {
+		return nil, err
+	}
+	program := &Program{
+		Enabled: values["en_program"] == "1",
+		Days:    make(map[string][]ProgramEntry),
+		Values:  values,
+	}
+	for _, day := range Weekdays {
+		entries, err := parseProgramDay(values[day])
+		if err != nil {
+			return nil, fmt.Errorf("invalid program for %s: %w", day, err)
+		}
+		program.Days[day] = entries
+	}
+	return program, nil
+}
+
+// SetProgram replaces the weekly program using context.
+func (c *Client) SetProgram(ctx context.Context, ip string, program Program) error {
+	params := []string{"en_program=" + boolParam(program.Enabled)}
+	for _, day := range Weekdays {
+		params = append(params, day+"="+url.QueryEscape(formatProgramDay(program.Days[day])))
+	}
+	_, err := c.get(ctx, ip, "aircon/set_program", strings.Join(params, "&"))
+	return err
+}
+
+// parseProgramDay decodes the entries of one day
+func parseProgramDay(s string) ([]ProgramEntry, error) {
+	if s == "" || s == "-" {
+		return nil, nil
+	}
+	var entries []ProgramEntry
+	for _, raw := range strings.Split(s, "/") {
+		fields := strings.Split(raw, "-")
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("malformed entry %q", raw)
+		}
+		entries = append(entries, ProgramEntry{Time: fields[0], Power: fields[1], Mode: fields[2], Temp: fields[3]})
+	}
+	return entries, nil
+}
+
+// formatProgramDay encodes the entries of one day
+func formatProgramDay(entries []ProgramEntry) string {
+	if len(entries) == 0 {
+		return "-"
+	}
+	parts := make([]string, 0, len(entries))
+	for _, e := range entries {
+		parts = append(parts, strings.Join([]string{e.Time, e.Power, e.Mode, e.Temp}, "-"))
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"sync"
+)
+
+// hostQueue allows a single in-flight request per adapter.
+// The wifi adapters drop connections or return garbage when they serve requests in parallel.
+type hostQueue struct {
+	mu    sync.Mutex
+	slots map[string]*hostSlot
+}
+
+// hostSlot is the queue of one host. users counts the requests holding or waiting
+// for it, so that the slot is dropped once the host goes idle.
+type hostSlot struct {
+	ch    chan struct{}
+	users int
+}
+
+func newHostQueue() *hostQueue {
+	return &hostQueue{slots: make(map[string]*hostSlot)}
+}
+
+// acquire waits for the slot of host and returns the function releasing it
+func (q *hostQueue) acquire(ctx context.Context, host string) (func(), error) {
+	q.mu.Lock()
+	slot, ok := q.slots[host]
+	if !ok {
+		slot = &hostSlot{ch: make(chan struct{}, 1)}
+		q.slots[host] = slot
+	}
+	slot.users++
+	q.mu.Unlock()
+
+	select {
+	case slot.ch <- struct{}{}:
+		return func() {
+			<-slot.ch
+			q.leave(host, slot)
+		}, nil
+	case <-ctx.Done():
+		q.leave(host, slot)
+		return nil, ctx.Err()
+	}
+}
+
+// leave drops a user of the slot of host and removes the slot when no request uses it
+func (q *hostQueue) leave(host string, slot *hostSlot) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	slot.users--
+	if slot.users == 0 {
+		delete(q.slots, host)
+	}
+}
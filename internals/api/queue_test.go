@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHostQueueDropsIdleHosts(t *testing.T) {
+	q := newHostQueue()
+	var inFlight, maxInFlight atomic.Int32
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := q.acquire(context.Background(), "10.0.0.1")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			n := inFlight.Add(1)
+			if n > maxInFlight.Load() {
+				maxInFlight.Store(n)
+			}
+			time.Sleep(time.Millisecond)
+			inFlight.Add(-1)
+			release()
+		}()
+	}
+
+	// A waiter giving up must not keep the host either
+	release, err := q.acquire(context.Background(), "10.0.0.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if _, err := q.acquire(ctx, "10.0.0.2"); err == nil {
+		t.Error("second acquire of a busy host succeeded")
+	}
+	release()
+
+	wg.Wait()
+	if n := maxInFlight.Load(); n != 1 {
+		t.Errorf("%d requests in flight for one host, want 1", n)
+	}
+	if n := len(q.slots); n != 0 {
+		t.Errorf("%d idle hosts left in the queue", n)
+	}
+}
@@ -0,0 +1,27 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+)
+
+// NewTerminalUUID returns a random terminal UUID (32 hex digits, no dashes) to register with an HTTPS adapter
+func NewTerminalUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // Version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return hex.EncodeToString(b), nil
+}
+
+// RegisterTerminal registers the endpoint UUID of ip with the adapter using context.
+// key is the 13-digit key printed on the adapter label. The client must have an
+// Endpoint with the UUID for ip; once registered, the adapter accepts every request carrying it.
+func (c *Client) RegisterTerminal(ctx context.Context, ip, key string) error {
+	_, err := c.get(ctx, ip, "common/register_terminal", url.Values{"key": {key}}.Encode())
+	return err
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"path"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// RetryPolicy controls how read requests are retried when an adapter drops
+// the connection or answers with garbage. Write requests are never retried.
+type RetryPolicy struct {
+	MaxAttempts int           // Attempts per request, including the first one (1 disables retries)
+	BaseDelay   time.Duration // Backoff before the first retry; doubled on each retry
+	MaxDelay    time.Duration // Upper bound of a single backoff
+	Budget      int           // Retries allowed per operation (0 means unlimited); see Client.WithRetryBudget
+}
+
+// DefaultRetryPolicy returns the policy used when WithRetry is not given.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    2 * time.Second,
+		Budget:      50,
+	}
+}
+
+// WithRetry sets the retry policy of the client.
+func WithRetry(policy RetryPolicy) Option {
+	return func(o *clientOptions) { o.retry = policy }
+}
+
+// retryBudgetKey is the context key of the retry budget of an operation
+type retryBudgetKey struct{}
+
+// WithRetryBudget returns a context carrying a fresh retry budget of the client policy.
+// Requests made with it, or with contexts derived from it, share that budget.
+// Requests without one draw from the budget of the Client, which suits a single command run;
+// long-running callers such as the TUIs start a new budget for each refresh or action.
+func (c *Client) WithRetryBudget(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryBudgetKey{}, newRetryBudget(c.retry.Budget))
+}
+
+// retryBudgetFrom returns the budget carried by ctx, or fallback when there is none
+func retryBudgetFrom(ctx context.Context, fallback *retryBudget) *retryBudget {
+	if b, ok := ctx.Value(retryBudgetKey{}).(*retryBudget); ok {
+		return b
+	}
+	return fallback
+}
+
+// retryBudget counts the retries left to an operation
+type retryBudget struct {
+	unlimited bool
+	left      atomic.Int64
+}
+
+func newRetryBudget(n int) *retryBudget {
+	b := &retryBudget{unlimited: n <= 0}
+	b.left.Store(int64(n))
+	return b
+}
+
+// take consumes one retry and reports whether it was available
+func (b *retryBudget) take() bool {
+	if b.unlimited {
+		return true
+	}
+	return b.left.Add(-1) >= 0
+}
+
+// backoff returns the jittered delay before the given retry (1 for the first retry).
+// The delay is drawn uniformly from [d/2, d] where d = BaseDelay * 2^(retry-1), capped at MaxDelay.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < retry && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
+
+// isIdempotent reports whether the endpoint only reads state and is safe to repeat
+func isIdempotent(endpoint string) bool {
+	name := path.Base(endpoint)
+	return strings.HasPrefix(name, "get_") || name == "basic_info"
+}
+
+// isRetryable reports whether err is a transient failure worth another attempt.
+// Failures caused by the caller's context are never retried.
+func isRetryable(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	var retErr *RetError
+	if errors.As(err, &retErr) {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+	return true
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SpecialMode is a boost/saving mode switched through /aircon/set_special_mode
+type SpecialMode string
+
+const (
+	SpecialPowerful SpecialMode = "powerful"
+	SpecialEcono    SpecialMode = "econo"
+	SpecialStreamer SpecialMode = "streamer"
+)
+
+// SpecialModes lists all known special modes
+var SpecialModes = []SpecialMode{SpecialPowerful, SpecialEcono, SpecialStreamer}
+
+// advCodes maps the codes found in control_info "adv" to special modes
+var advCodes = map[string]SpecialMode{
+	"2":  SpecialPowerful,
+	"12": SpecialEcono,
+	"13": SpecialStreamer,
+}
+
+// SpecialModeSetting turns a special mode on or off
+type SpecialModeSetting struct {
+	Mode SpecialMode
+	On   bool
+}
+
+func (s SpecialModeSetting) String() string {
+	if s.On {
+		return string(s.Mode) + "=on"
+	}
+	return string(s.Mode) + "=off"
+}
+
+// ParseSpecialModeSetting parses "powerful", "econo=on" or "streamer=off"
+func ParseSpecialModeSetting(s string) (SpecialModeSetting, error) {
+	name, state, hasState := strings.Cut(strings.ToLower(strings.TrimSpace(s)), "=")
+	setting := SpecialModeSetting{Mode: SpecialMode(name), On: true}
+
+	known := false
+	for _, m := range SpecialModes {
+		if m == setting.Mode {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return setting, fmt.Errorf("unknown special mode %q (use powerful, econo or streamer)", name)
+	}
+
+	if hasState {
+		switch state {
+		case "on", "1":
+			setting.On = true
+		case "off", "0":
+			setting.On = false
+		default:
+			return setting, fmt.Errorf("special mode state %q must be on or off", state)
+		}
+	}
+	return setting, nil
+}
+
+// ActiveSpecialModes returns the special modes reported as active in "adv"
+func (c ControlInfo) ActiveSpecialModes() []SpecialMode {
+	var active []SpecialMode
+	for _, code := range strings.Split(c.Advanced, "/") {
+		if mode, ok := advCodes[code]; ok {
+			active = append(active, mode)
+		}
+	}
+	return active
+}
+
+// SetSpecialMode turns a special mode on or off using context.
+func (c *Client) SetSpecialMode(ctx context.Context, ip string, setting SpecialModeSetting) error {
+	var query string
+	switch setting.Mode {
+	case SpecialPowerful:
+		query = "set_spmode=" + boolParam(setting.On) + "&spmode_kind=1"
+	case SpecialEcono:
+		query = "set_spmode=" + boolParam(setting.On) + "&spmode_kind=2"
+	case SpecialStreamer:
+		query = "en_streamer=" + boolParam(setting.On)
+	default:
+		return fmt.Errorf("unknown special mode %q", setting.Mode)
+	}
+	_, err := c.get(ctx, ip, "aircon/set_special_mode", query)
+	return err
+}
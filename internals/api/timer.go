@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Timer is the on/off delay timer stored on the adapter.
+// Delays are counted by the adapter from the moment they are set.
+type Timer struct {
+	OnEnabled  bool
+	OnDelay    time.Duration // on_timer, minute resolution
+	OffEnabled bool
+	OffDelay   time.Duration // off_timer, minute resolution
+	Values     map[string]string
+}
+
+// FetchTimer fetches the on/off timer state using context.
+func (c *Client) FetchTimer(ctx context.Context, ip string) (*Timer, error) {
+	values, err := c.get(ctx, ip, "aircon/get_timer", "")
+	if err != nil {
+		return nil, err
+	}
+	onMinutes, _ := strconv.Atoi(values["on_timer"])
+	offMinutes, _ := strconv.Atoi(values["off_timer"])
+	return &Timer{
+		OnEnabled:  values["en_on_timer"] == "1",
+		OnDelay:    time.Duration(onMinutes) * time.Minute,
+		OffEnabled: values["en_off_timer"] == "1",
+		OffDelay:   time.Duration(offMinutes) * time.Minute,
+		Values:     values,
+	}, nil
+}
+
+// SetTimer replaces the on/off timer using context. Disabled timers are sent with a zero delay.
+func (c *Client) SetTimer(ctx context.Context, ip string, timer Timer) error {
+	onMinutes, offMinutes := 0, 0
+	if timer.OnEnabled {
+		onMinutes = int(timer.OnDelay / time.Minute)
+	}
+	if timer.OffEnabled {
+		offMinutes = int(timer.OffDelay / time.Minute)
+	}
+	query := fmt.Sprintf("en_on_timer=%s&on_timer=%d&en_off_timer=%s&off_timer=%d",
+		boolParam(timer.OnEnabled), onMinutes, boolParam(timer.OffEnabled), offMinutes)
+	_, err := c.get(ctx, ip, "aircon/set_timer", query)
+	return err
+}
+
+// boolParam encodes a boolean as the adapters' "0"/"1" flags
+func boolParam(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
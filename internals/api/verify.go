@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultVerifyInterval is the delay between two get_control_info polls while verifying
+const DefaultVerifyInterval = 500 * time.Millisecond
+
+// FieldMismatch is a control field that the unit reports with another value than requested
+type FieldMismatch struct {
+	Field string // Adapter key, e.g. "stemp"
+	Want  string
+	Got   string
+}
+
+func (m FieldMismatch) String() string {
+	return fmt.Sprintf("%s: want %s, got %s", m.Field, m.Want, m.Got)
+}
+
+// VerifyError reports the fields that still differed when verification gave up
+type VerifyError struct {
+	IP         string
+	Mismatches []FieldMismatch
+}
+
+func (e *VerifyError) Error() string {
+	parts := make([]string, 0, len(e.Mismatches))
+	for _, m := range e.Mismatches {
+		parts = append(parts, m.String())
+	}
+	return fmt.Sprintf("%s did not take the settings (%s)", e.IP, strings.Join(parts, "; "))
+}
+
+// CompareClim returns the pow, mode, stemp, f_rate and f_dir fields of want that differ in got.
+// Empty fields of want are not compared. stemp is not compared in DRY and FAN modes,
+// where the units report a placeholder instead of a temperature.
+func CompareClim(want, got Clim) []FieldMismatch {
+	var mismatches []FieldMismatch
+	check := func(field, w, g string, equal func(a, b string) bool) {
+		if w != "" && !equal(w, g) {
+			mismatches = append(mismatches, FieldMismatch{Field: field, Want: w, Got: g})
+		}
+	}
+	same := func(a, b string) bool { return a == b }
+
+	check("pow", want.Power, got.Power, same)
+	check("mode", want.Mode, got.Mode, same)
+	if want.Mode != string(ModeDry) && want.Mode != string(ModeFan) {
+		check("stemp", want.Temp, got.Temp, sameTemp)
+	}
+	check("f_rate", want.FanRate, got.FanRate, same)
+	check("f_dir", want.FanDir, got.FanDir, same)
+	return mismatches
+}
+
+// sameTemp compares temperatures numerically ("22" equals "22.0")
+func sameTemp(a, b string) bool {
+	fa, errA := strconv.ParseFloat(a, 64)
+	fb, errB := strconv.ParseFloat(b, 64)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return fa-fb < 0.05 && fb-fa < 0.05
+}
+
+// VerifyClim polls get_control_info until the unit reports the requested settings
+// or ctx is done. It returns a *VerifyError listing the fields that still differ,
+// or the read error when the unit could not be read at all.
+func (c *Client) VerifyClim(ctx context.Context, want Clim, interval time.Duration) error {
+	if interval <= 0 {
+		interval = DefaultVerifyInterval
+	}
+
+	var mismatches []FieldMismatch
+	var readErr error
+	for {
+		info, err := c.FetchControlInfo(ctx, want.IP)
+		if err == nil {
+			mismatches = CompareClim(want, info.Clim(want.IP))
+			if len(mismatches) == 0 {
+				return nil
+			}
+		} else if ctx.Err() == nil {
+			readErr = err
+		}
+
+		if err := sleep(ctx, interval); err != nil {
+			break
+		}
+	}
+
+	if mismatches == nil {
+		if readErr == nil {
+			readErr = ctx.Err()
+		}
+		return fmt.Errorf("could not read back %s: %w", want.IP, readErr)
+	}
+	return &VerifyError{IP: want.IP, Mismatches: mismatches}
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Zones is the decoded response of /aircon/get_zone_setting (AirBase ducted units).
+// Names and On have one entry per zone, in adapter order.
+type Zones struct {
+	Names  []string
+	On     []bool
+	Values map[string]string
+}
+
+// FetchZones fetches the zone names and on/off states using context.
+func (c *Client) FetchZones(ctx context.Context, ip string) (*Zones, error) {
+	values, err := c.get(ctx, ip, "aircon/get_zone_setting", "")
+	if err != nil {
+		return nil, err
+	}
+
+	zones := &Zones{Values: values}
+	if values["zone_name"] != "" {
+		zones.Names = strings.Split(values["zone_name"], ";")
+	}
+	for _, s := range strings.Split(values["zone_onoff"], ";") {
+		if s != "" {
+			zones.On = append(zones.On, s == "1")
+		}
+	}
+	if len(zones.On) != len(zones.Names) {
+		return nil, fmt.Errorf("get_zone_setting: %d zone names but %d states", len(zones.Names), len(zones.On))
+	}
+	return zones, nil
+}
+
+// SetZones writes the zone on/off states using context.
+// The adapter expects the names along with the states, both ";"-separated.
+// Each name is escaped on its own so that a ";" in a name does not split it.
+func (c *Client) SetZones(ctx context.Context, ip string, zones Zones) error {
+	names := make([]string, len(zones.Names))
+	for i, name := range zones.Names {
+		names[i] = url.QueryEscape(name)
+	}
+	states := make([]string, len(zones.On))
+	for i, on := range zones.On {
+		states[i] = boolParam(on)
+	}
+	query := "zone_name=" + strings.Join(names, ";") +
+		"&zone_onoff=" + strings.Join(states, ";")
+	_, err := c.get(ctx, ip, "aircon/set_zone_setting", query)
+	return err
+}
@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/romaingallez/clim_cli/internals/api"
+	"github.com/spf13/cobra"
+)
+
+// AdminLED turns the status LED of the targeted adapters on or off
+func AdminLED(cmd *cobra.Command, args []string) {
+	on, err := parseOnOff(args[0])
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	runAdmin(cmd, fmt.Sprintf("LED turned %s", args[0]), func(ctx context.Context, client *api.Client, ip string) error {
+		return client.SetLED(ctx, ip, on)
+	})
+}
+
+// AdminHoliday enables or disables holiday mode on the targeted adapters
+func AdminHoliday(cmd *cobra.Command, args []string) {
+	on, err := parseOnOff(args[0])
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	runAdmin(cmd, fmt.Sprintf("Holiday mode turned %s", args[0]), func(ctx context.Context, client *api.Client, ip string) error {
+		return client.SetHoliday(ctx, ip, on)
+	})
+}
+
+// AdminReboot restarts the targeted adapters.
+// Rebooting more than one adapter needs --yes or an interactive confirmation.
+func AdminReboot(cmd *cobra.Command, args []string) {
+	targets, err := resolveTargets(cmd)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if yes, _ := cmd.Flags().GetBool("yes"); len(targets) > 1 && !yes && !confirmTargets("Reboot", targets) {
+		fmt.Println("Reboot cancelled")
+		return
+	}
+	runAdminTargets(targets, "Reboot requested", func(ctx context.Context, client *api.Client, ip string) error {
+		return client.Reboot(ctx, ip)
+	})
+}
+
+// AdminSyncTime pushes the local date and time to the targeted adapters
+func AdminSyncTime(cmd *cobra.Command, args []string) {
+	runAdmin(cmd, "Clock synchronized", func(ctx context.Context, client *api.Client, ip string) error {
+		return client.NotifyDateTime(ctx, ip, time.Now())
+	})
+}
+
+// runAdmin applies action to every targeted device and prints a summary
+func runAdmin(cmd *cobra.Command, done string, action func(ctx context.Context, client *api.Client, ip string) error) {
+	targets, err := resolveTargets(cmd)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	runAdminTargets(targets, done, action)
+}
+
+// runAdminTargets applies action to every target and prints a summary
+func runAdminTargets(targets []target, done string, action func(ctx context.Context, client *api.Client, ip string) error) {
+	client := NewAPIClient()
+	totalSuccess := 0
+	totalFailed := 0
+
+	for _, t := range targets {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err := action(ctx, client, t.IP)
+		cancel()
+		if err != nil {
+			fmt.Printf("%s: Error: %v\n", t.label(), err)
+			totalFailed++
+			continue
+		}
+		fmt.Printf("%s: ✓ %s\n", t.label(), done)
+		totalSuccess++
+	}
+
+	if len(targets) > 1 {
+		fmt.Printf("\n=== Summary ===\n")
+		fmt.Printf("Total devices processed: %d\n", len(targets))
+		fmt.Printf("Successful: %d\n", totalSuccess)
+		fmt.Printf("Failed: %d\n", totalFailed)
+	}
+}
+
+// confirmTargets lists the targets and asks on the terminal whether to run action on them.
+// It returns false without asking when stdin is not a terminal.
+func confirmTargets(action string, targets []target) bool {
+	if fd := os.Stdin.Fd(); !isatty.IsTerminal(fd) && !isatty.IsCygwinTerminal(fd) {
+		fmt.Printf("Error: %s of %d devices needs confirmation: pass --yes\n", action, len(targets))
+		return false
+	}
+	fmt.Printf("%s %d devices:\n", action, len(targets))
+	for _, t := range targets {
+		fmt.Printf("  - %s\n", t.label())
+	}
+	fmt.Print("Continue? [y/N] ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// parseOnOff parses an "on"/"off" argument
+func parseOnOff(s string) (bool, error) {
+	switch s {
+	case "on":
+		return true, nil
+	case "off":
+		return false, nil
+	}
+	return false, fmt.Errorf("expected on or off, got %q", s)
+}
@@ -52,6 +52,7 @@ func batchClimFromScript(cmd *cobra.Command, scriptPath string) {
 		return
 	}
 
+	client := NewAPIClient()
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -59,6 +60,8 @@ func batchClimFromScript(cmd *cobra.Command, scriptPath string) {
 	totalProcessed := 0
 	totalSuccess := 0
 	totalFailed := 0
+	totalNotVerified := 0
+	verify := getVerifyTimeout(cmd)
 
 	for _, groupConfig := range script.Groups {
 		fmt.Printf("\n=== Processing group: %s ===\n", groupConfig.GroupName)
@@ -83,10 +86,12 @@ func batchClimFromScript(cmd *cobra.Command, scriptPath string) {
 			params := mergeParams(groupConfig.Params, override)
 
 			// Apply settings to device
-			success := applySettingsToDevice(ctx, device, params)
-			if success {
+			switch applySettingsToDevice(ctx, client, device, params, verify) {
+			case applyOK:
 				totalSuccess++
-			} else {
+			case applyNotVerified:
+				totalNotVerified++
+			default:
 				totalFailed++
 			}
 		}
@@ -97,6 +102,12 @@ func batchClimFromScript(cmd *cobra.Command, scriptPath string) {
 	fmt.Printf("Total devices processed: %d\n", totalProcessed)
 	fmt.Printf("Successful: %d\n", totalSuccess)
 	fmt.Printf("Failed: %d\n", totalFailed)
+	if verify > 0 {
+		fmt.Printf("Not verified: %d\n", totalNotVerified)
+	}
+	if totalNotVerified > 0 {
+		os.Exit(ExitVerifyFailed)
+	}
 }
 
 // batchClimFromFlags handles simple batch operations from command-line flags
@@ -107,6 +118,7 @@ func batchClimFromFlags(cmd *cobra.Command, groupName string) {
 	temp, _ := cmd.Flags().GetString("temp")
 	fanRate, _ := cmd.Flags().GetString("fan-rate")
 	fanDir, _ := cmd.Flags().GetString("fan-dir")
+	special, _ := cmd.Flags().GetStringSlice("special")
 
 	// Build params from flags
 	params := ClimParams{
@@ -115,11 +127,12 @@ func batchClimFromFlags(cmd *cobra.Command, groupName string) {
 		Temp:    temp,
 		FanRate: fanRate,
 		FanDir:  fanDir,
+		Special: special,
 	}
 
 	// Check if at least one parameter is provided
-	if power == "" && mode == "" && temp == "" && fanRate == "" && fanDir == "" {
-		fmt.Println("Error: At least one parameter (--power, --mode, --temp, --fan-rate, --fan-dir) must be provided")
+	if power == "" && mode == "" && temp == "" && fanRate == "" && fanDir == "" && len(special) == 0 {
+		fmt.Println("Error: At least one parameter (--power, --mode, --temp, --fan-rate, --fan-dir, --special) must be provided")
 		return
 	}
 
@@ -144,6 +157,7 @@ func batchClimFromFlags(cmd *cobra.Command, groupName string) {
 
 	fmt.Printf("Found %d device(s) in group: %s\n", len(matchingDevices), groupName)
 
+	client := NewAPIClient()
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -151,13 +165,17 @@ func batchClimFromFlags(cmd *cobra.Command, groupName string) {
 	totalProcessed := 0
 	totalSuccess := 0
 	totalFailed := 0
+	totalNotVerified := 0
+	verify := getVerifyTimeout(cmd)
 
 	for _, device := range matchingDevices {
 		totalProcessed++
-		success := applySettingsToDevice(ctx, device, params)
-		if success {
+		switch applySettingsToDevice(ctx, client, device, params, verify) {
+		case applyOK:
 			totalSuccess++
-		} else {
+		case applyNotVerified:
+			totalNotVerified++
+		default:
 			totalFailed++
 		}
 	}
@@ -167,6 +185,12 @@ func batchClimFromFlags(cmd *cobra.Command, groupName string) {
 	fmt.Printf("Total devices processed: %d\n", totalProcessed)
 	fmt.Printf("Successful: %d\n", totalSuccess)
 	fmt.Printf("Failed: %d\n", totalFailed)
+	if verify > 0 {
+		fmt.Printf("Not verified: %d\n", totalNotVerified)
+	}
+	if totalNotVerified > 0 {
+		os.Exit(ExitVerifyFailed)
+	}
 }
 
 // loadBatchScript loads and parses the JSON script file
@@ -244,27 +268,39 @@ func mergeParams(defaults ClimParams, override *ClimParams) ClimParams {
 	if override.FanDir != "" {
 		result.FanDir = override.FanDir
 	}
+	if len(override.Special) > 0 {
+		result.Special = override.Special
+	}
 
 	return result
 }
 
-// applySettingsToDevice applies settings to a single device
-// Returns true on success, false on failure
-func applySettingsToDevice(ctx context.Context, device *storage.DeviceHistory, params ClimParams) bool {
+// applyOutcome is the result of applying settings to a single device
+type applyOutcome int
+
+const (
+	applyOK          applyOutcome = iota
+	applyFailed                   // Rejected, unreachable or unsupported
+	applyNotVerified              // Accepted by the adapter but not reported back by the unit
+)
+
+// applySettingsToDevice applies settings to a single device.
+// When verify is not zero, the settings are read back until they match or verify elapses.
+func applySettingsToDevice(ctx context.Context, client *api.Client, device *storage.DeviceHistory, params ClimParams, verify time.Duration) applyOutcome {
 	deviceIP := device.Device.IP
 	deviceName := device.Device.Name
 
 	fmt.Printf("\n  Device: %s (%s)\n", deviceName, deviceIP)
 
 	// Fetch current settings
-	currentControlInfo, err := api.FetchControlInfo(ctx, deviceIP)
+	currentControlInfo, err := client.FetchControlInfo(ctx, deviceIP)
 	if err != nil {
 		fmt.Printf("    Error: Failed to fetch current settings: %v\n", err)
-		return false
+		return applyFailed
 	}
 
 	// Build current Clim from device response
-	currentClim := buildClimFromControlInfoForBatch(deviceIP, currentControlInfo)
+	currentClim := currentControlInfo.Clim(deviceIP)
 
 	// Build new Clim: merge current settings with script params
 	// Empty string in params means "keep current value"
@@ -278,17 +314,54 @@ func applySettingsToDevice(ctx context.Context, device *storage.DeviceHistory, p
 		FanDir:  getValueOrDefault(params.FanDir, currentClim.FanDir),
 	}
 
+	specials, err := parseSpecialModeSettings(params.Special)
+	if err != nil {
+		fmt.Printf("    Error: %v\n", err)
+		return applyFailed
+	}
+
+	// Validate against what this unit supports
+	caps := device.Capabilities()
+	if err := caps.Validate(newClim); err != nil {
+		fmt.Printf("    Error: Unsupported settings: %v\n", err)
+		return applyFailed
+	}
+	for _, special := range specials {
+		if err := caps.ValidateSpecialMode(special); err != nil {
+			fmt.Printf("    Error: Unsupported settings: %v\n", err)
+			return applyFailed
+		}
+	}
+
 	// Display what will be changed
 	displayChangesBatch(currentClim, newClim)
 
 	// Apply new settings
-	if err := api.SetClim(ctx, newClim); err != nil {
-		fmt.Printf("    Error: Failed to apply settings: %v\n", err)
-		return false
+	if err := client.SetClim(ctx, newClim); err != nil {
+		fmt.Printf("    Error: Failed to apply settings: %s\n", describeSetError(err, newClim))
+		return applyFailed
+	}
+
+	// Apply special modes once the base settings are accepted
+	for _, special := range specials {
+		if err := client.SetSpecialMode(ctx, deviceIP, special); err != nil {
+			fmt.Printf("    Error: Failed to set special mode %s: %v\n", special, err)
+			return applyFailed
+		}
+		fmt.Printf("    Special mode: %s\n", special)
+	}
+
+	if verify > 0 {
+		if err := verifyClim(client, newClim, verify); err != nil {
+			printVerifyError(err, "    ")
+			return applyNotVerified
+		}
+		fmt.Printf("    ✓ Settings applied and verified\n")
+		return applyOK
 	}
 
 	fmt.Printf("    ✓ Settings applied successfully\n")
-	return true
+	return applyOK
 }
 
 // getValueOrDefault returns the value if not empty, otherwise returns the default
@@ -299,19 +372,6 @@ func getValueOrDefault(value, defaultValue string) string {
 	return value
 }
 
-// buildClimFromControlInfoForBatch builds a Clim struct from the API control info response
-func buildClimFromControlInfoForBatch(ip string, controlInfo map[string]string) api.Clim {
-	return api.Clim{
-		IP:      ip,
-		Power:   controlInfo["pow"],
-		Mode:    controlInfo["mode"],
-		Temp:    controlInfo["stemp"],
-		Shum:    controlInfo["shum"],
-		FanRate: controlInfo["f_rate"],
-		FanDir:  controlInfo["f_dir"],
-	}
-}
-
 // displayChangesBatch shows what settings are being changed
 // (Similar to displayChanges in set_clim.go but adapted for batch output)
 func displayChangesBatch(current, new api.Clim) {
@@ -342,21 +402,7 @@ func displayChangesBatch(current, new api.Clim) {
 	}
 
 	if current.FanDir != new.FanDir {
-		fanDirNames := map[string]string{
-			"0": "All wings stopped",
-			"1": "Vertical wings motion",
-			"2": "Horizontal wings motion",
-			"3": "Vertical and horizontal wings motion",
-		}
-		oldFanDirName := fanDirNames[current.FanDir]
-		if oldFanDirName == "" {
-			oldFanDirName = current.FanDir
-		}
-		newFanDirName := fanDirNames[new.FanDir]
-		if newFanDirName == "" {
-			newFanDirName = new.FanDir
-		}
-		changes = append(changes, fmt.Sprintf("Fan Dir: %s (%s) → %s (%s)", oldFanDirName, current.FanDir, newFanDirName, new.FanDir))
+		changes = append(changes, fmt.Sprintf("Fan Dir: %s (%s) → %s (%s)", api.FanDir(current.FanDir), current.FanDir, api.FanDir(new.FanDir), new.FanDir))
 	}
 
 	if len(changes) > 0 {
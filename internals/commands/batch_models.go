@@ -8,6 +8,7 @@ type ClimParams struct {
 	Temp    string `json:"temp,omitempty"`    // Temperature (e.g., "22.0")
 	FanRate string `json:"fan-rate,omitempty"` // Fan rate (e.g., "A", "3"-"7")
 	FanDir  string `json:"fan-dir,omitempty"`  // Fan direction: "0"=all wings stopped, "1"=vertical, "2"=horizontal, "3"=both
+	Special []string `json:"special,omitempty"` // Special modes: "powerful", "econo", "streamer", optionally suffixed with "=on"/"=off"
 }
 
 // DeviceOverride represents per-device parameter overrides
@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/romaingallez/clim_cli/internals/api"
+)
+
+// cassette is the record or replay middleware added to every client, nil when neither is set
+var cassette api.Middleware
+
+// UseCassette makes the api clients record their traffic to recordDir or replay it from replayDir.
+// Empty directories leave the clients untouched.
+func UseCassette(recordDir, replayDir string) error {
+	switch {
+	case recordDir != "" && replayDir != "":
+		return errors.New("--record and --replay cannot be used together")
+	case recordDir != "":
+		c, err := api.OpenCassette(recordDir)
+		if err != nil {
+			return err
+		}
+		cassette = c.Record()
+		fmt.Fprintf(os.Stderr, "Recording adapter traffic to %s\n", c.Path())
+	case replayDir != "":
+		if _, err := os.Stat(replayDir); err != nil {
+			return fmt.Errorf("cannot replay from %s: %w", replayDir, err)
+		}
+		c, err := api.OpenCassette(replayDir)
+		if err != nil {
+			return err
+		}
+		if len(c.Interactions) == 0 {
+			return fmt.Errorf("cannot replay from %s: no recorded interactions", c.Path())
+		}
+		cassette = c.Replay()
+		fmt.Fprintf(os.Stderr, "Replaying adapter traffic from %s\n", c.Path())
+	}
+	return nil
+}
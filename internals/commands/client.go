@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"github.com/romaingallez/clim_cli/internals/api"
+	"github.com/romaingallez/clim_cli/internals/config"
+	"github.com/romaingallez/clim_cli/internals/storage"
+	"github.com/romaingallez/clim_cli/internals/version"
+)
+
+// NewAPIClient creates the api client used by the commands and the TUIs from the api
+// config section and the transport settings of the stored devices, recording or replaying
+// its traffic when --record or --replay is set. opts are applied last.
+func NewAPIClient(opts ...api.Option) *api.Client {
+	cfg := config.GetAPIConfig()
+	base := []api.Option{
+		api.WithUserAgent("clim_cli/" + version.Version),
+		api.WithRetry(api.RetryPolicy{
+			MaxAttempts: cfg.MaxAttempts,
+			BaseDelay:   cfg.RetryBaseDelay,
+			MaxDelay:    cfg.RetryMaxDelay,
+			Budget:      cfg.RetryBudget,
+		}),
+	}
+	if cfg.Timeout > 0 {
+		base = append(base, api.WithTimeout(cfg.Timeout))
+	}
+	if endpoints := storedEndpoints(); len(endpoints) > 0 {
+		base = append(base, api.WithEndpoints(endpoints))
+	}
+	if cassette != nil {
+		base = append(base, api.WithMiddleware(cassette))
+	}
+	return api.NewClient(append(base, opts...)...)
+}
+
+// storedEndpoints returns the transport settings and dialect of the stored devices, keyed by IP
+func storedEndpoints() map[string]api.Endpoint {
+	histories, err := storage.GetDeviceHistories()
+	if err != nil {
+		return nil
+	}
+	endpoints := make(map[string]api.Endpoint)
+	for _, h := range histories {
+		ep := api.Endpoint{Dialect: h.Device.Dialect}
+		if h.Transport != nil {
+			ep.Scheme = h.Transport.Scheme
+			ep.UUID = h.Transport.UUID
+		}
+		if ep != (api.Endpoint{}) {
+			endpoints[h.Device.IP] = ep
+		}
+	}
+	return endpoints
+}
@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/romaingallez/clim_cli/internals/api"
+	"github.com/spf13/cobra"
+)
+
+// Limits of the power cap accepted by the adapters, in percent of the rated power
+const (
+	minDemandPercent = 40
+	maxDemandPercent = 100
+)
+
+// DemandGet prints the demand control settings of the targeted devices
+func DemandGet(cmd *cobra.Command, args []string) {
+	client := NewAPIClient()
+	targets, err := resolveTargets(cmd)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	for _, t := range targets {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		demand, err := client.FetchDemandControl(ctx, t.IP)
+		cancel()
+		if err != nil {
+			fmt.Printf("%s: Error: Failed to fetch demand control: %v\n", t.label(), err)
+			continue
+		}
+		fmt.Printf("%s: %s\n", t.label(), formatDemand(demand))
+	}
+}
+
+// DemandSet sets a fixed power cap on the targeted devices.
+// The schedule fields reported by each device are written back unchanged.
+func DemandSet(cmd *cobra.Command, args []string) {
+	if !requireExperimental(cmd) {
+		return
+	}
+	if !cmd.Flags().Changed("percent") {
+		fmt.Println("Error: --percent is required")
+		return
+	}
+	percent, _ := cmd.Flags().GetInt("percent")
+	if err := validateDemandPercent(percent); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	targets, err := resolveTargets(cmd)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	applyDemand(NewAPIClient(), targets, func(demand *api.DemandControl) {
+		demand.Enabled = true
+		demand.Mode = api.DemandModeManual
+		demand.MaxPower = percent
+	})
+}
+
+// DemandOff removes the power cap from the targeted devices
+func DemandOff(cmd *cobra.Command, args []string) {
+	if !requireExperimental(cmd) {
+		return
+	}
+	targets, err := resolveTargets(cmd)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	applyDemand(NewAPIClient(), targets, func(demand *api.DemandControl) {
+		demand.Enabled = false
+	})
+}
+
+// applyDemand reads the demand control settings of every target, changes them with
+// update and writes them back
+func applyDemand(client *api.Client, targets []target, update func(*api.DemandControl)) {
+	totalSuccess := 0
+	totalFailed := 0
+
+	for _, t := range targets {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		demand, err := client.FetchDemandControl(ctx, t.IP)
+		if err == nil {
+			update(demand)
+			err = client.SetDemandControl(ctx, t.IP, *demand)
+		}
+		cancel()
+		if err != nil {
+			fmt.Printf("%s: Error: Failed to set demand control: %v\n", t.label(), err)
+			totalFailed++
+			continue
+		}
+		fmt.Printf("%s: ✓ %s\n", t.label(), formatDemand(demand))
+		totalSuccess++
+	}
+
+	if len(targets) > 1 {
+		fmt.Printf("\n=== Summary ===\n")
+		fmt.Printf("Successful: %d\n", totalSuccess)
+		fmt.Printf("Failed: %d\n", totalFailed)
+	}
+}
+
+// validateDemandPercent checks a power cap percentage
+func validateDemandPercent(percent int) error {
+	if percent < minDemandPercent || percent > maxDemandPercent {
+		return fmt.Errorf("power cap must be between %d and %d percent", minDemandPercent, maxDemandPercent)
+	}
+	return nil
+}
+
+// formatDemand renders the demand control settings on one line
+func formatDemand(demand *api.DemandControl) string {
+	var b strings.Builder
+	switch {
+	case !demand.Enabled:
+		b.WriteString("no power cap")
+	case demand.Mode == api.DemandModeManual:
+		fmt.Fprintf(&b, "power capped at %d%%", demand.MaxPower)
+	default:
+		fmt.Fprintf(&b, "power cap mode %s, max %d%%", demand.Mode, demand.MaxPower)
+	}
+	if demand.SchedulesPerDay != "" {
+		fmt.Fprintf(&b, " (scdl_per_day=%s", demand.SchedulesPerDay)
+		for _, day := range api.Weekdays {
+			if v, ok := demand.Days[day]; ok {
+				fmt.Fprintf(&b, " %s=%s", day, v)
+			}
+		}
+		b.WriteString(")")
+	}
+	return b.String()
+}
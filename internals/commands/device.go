@@ -0,0 +1,327 @@
+package commands
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/romaingallez/clim_cli/internals/api"
+	"github.com/romaingallez/clim_cli/internals/search"
+	"github.com/romaingallez/clim_cli/internals/storage"
+	"github.com/spf13/cobra"
+)
+
+// identityChange is a rename and/or regroup of a single device
+type identityChange struct {
+	target target
+	name   string
+	group  string
+}
+
+// DeviceRename changes the name of a device, or of many devices from a CSV file
+func DeviceRename(cmd *cobra.Command, args []string) {
+	runIdentityChanges(cmd, args, "name")
+}
+
+// DeviceSetGroup changes the group name (grp_name) of a device, or of many devices from a CSV file
+func DeviceSetGroup(cmd *cobra.Command, args []string) {
+	runIdentityChanges(cmd, args, "group")
+}
+
+// DeviceImport renames and regroups many devices from a CSV file with "mac", "name" and "group" columns
+func DeviceImport(cmd *cobra.Command, args []string) {
+	changes, err := loadIdentityCSV(args[0], "name", "group")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if len(changes) == 0 {
+		fmt.Println("No changes to apply.")
+		return
+	}
+
+	applyIdentityChanges(NewAPIClient(), changes)
+}
+
+// runIdentityChanges resolves the changes from the arguments or --csv and applies the given field
+func runIdentityChanges(cmd *cobra.Command, args []string, field string) {
+	csvPath, _ := cmd.Flags().GetString("csv")
+
+	var changes []identityChange
+	var err error
+	if csvPath != "" {
+		if len(args) > 0 {
+			fmt.Println("Error: Pass either a value or --csv, not both")
+			return
+		}
+		changes, err = loadIdentityCSV(csvPath, field)
+	} else {
+		if len(args) != 1 {
+			fmt.Printf("Error: Expected the new %s as argument, or --csv\n", field)
+			return
+		}
+		changes, err = singleIdentityChange(cmd, args[0], field)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if len(changes) == 0 {
+		fmt.Println("No changes to apply.")
+		return
+	}
+
+	applyIdentityChanges(NewAPIClient(), changes)
+}
+
+// singleIdentityChange builds a change for the device selected by --mac or --ip
+func singleIdentityChange(cmd *cobra.Command, value, field string) ([]identityChange, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, fmt.Errorf("the new %s must not be empty", field)
+	}
+
+	t, err := resolveSingleTarget(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	change := identityChange{target: t}
+	if field == "name" {
+		change.name = value
+	} else {
+		change.group = value
+	}
+	return []identityChange{change}, nil
+}
+
+// resolveSingleTarget selects the device given by --mac, or by --ip (falling back to the configured default)
+func resolveSingleTarget(cmd *cobra.Command) (target, error) {
+	if mac, _ := cmd.Flags().GetString("mac"); mac != "" {
+		history, err := findDeviceByMAC(mac)
+		if err != nil {
+			return target{}, err
+		}
+		return targetFromHistory(history), nil
+	}
+	targets, err := resolveTargets(cmd)
+	if err != nil {
+		return target{}, err
+	}
+	return targets[0], nil
+}
+
+// loadIdentityCSV reads a CSV with a "mac" column and "name" and/or "group" columns.
+// Only the columns matching fields are used and at least one of them must be present;
+// empty values are left unchanged and rows without any value are skipped.
+func loadIdentityCSV(path string, fields ...string) ([]identityChange, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open CSV file: %w", err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.TrimLeadingSpace = true
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	macCol, nameCol, groupCol := -1, -1, -1
+	for i, h := range header {
+		switch col := strings.ToLower(strings.TrimSpace(h)); {
+		case col == "mac":
+			macCol = i
+		case col == "name" && slices.Contains(fields, "name"):
+			nameCol = i
+		case col == "group" && slices.Contains(fields, "group"):
+			groupCol = i
+		}
+	}
+	if macCol < 0 || (nameCol < 0 && groupCol < 0) {
+		return nil, fmt.Errorf("CSV header must contain a \"mac\" column and a %q column", strings.Join(fields, "\" or \""))
+	}
+
+	var changes []identityChange
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV line %d: %w", line, err)
+		}
+		column := func(i int) string {
+			if i < 0 {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		change := identityChange{name: column(nameCol), group: column(groupCol)}
+		if change.name == "" && change.group == "" {
+			continue
+		}
+		history, err := findDeviceByMAC(record[macCol])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		change.target = targetFromHistory(history)
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// applyIdentityChanges writes the changes to the devices, then records them in storage
+func applyIdentityChanges(client *api.Client, changes []identityChange) {
+	var updates []storage.IdentityUpdate
+	totalSuccess := 0
+	totalFailed := 0
+
+	for _, c := range changes {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		// Record what the device accepted, even when only the name or the group was set
+		applied := storage.IdentityUpdate{MAC: c.target.MAC}
+		var err error
+		if c.name != "" {
+			if err = client.SetName(ctx, c.target.IP, c.name); err != nil {
+				fmt.Printf("%s: Error: Failed to set name → %q: %v\n", c.target.label(), c.name, err)
+			} else {
+				fmt.Printf("%s: ✓ name → %q\n", c.target.label(), c.name)
+				applied.Name = c.name
+			}
+		}
+		if c.group != "" && err == nil {
+			if err = client.SetGroupName(ctx, c.target.IP, c.group); err != nil {
+				fmt.Printf("%s: Error: Failed to set group → %q: %v\n", c.target.label(), c.group, err)
+			} else {
+				fmt.Printf("%s: ✓ group → %q\n", c.target.label(), c.group)
+				applied.Group = c.group
+			}
+		}
+		cancel()
+		if err != nil {
+			totalFailed++
+		} else {
+			totalSuccess++
+		}
+
+		if applied.Name == "" && applied.Group == "" {
+			continue
+		}
+		if c.target.MAC == "" {
+			fmt.Printf("%s: not in storage, run 'clim_cli search' to record it\n", c.target.IP)
+			continue
+		}
+		updates = append(updates, applied)
+	}
+
+	if len(updates) > 0 {
+		if err := storage.UpdateDeviceIdentities(updates); err != nil {
+			log.Printf("Warning: Failed to update device storage: %v", err)
+		}
+	}
+
+	if len(changes) > 1 {
+		fmt.Printf("\n=== Summary ===\n")
+		fmt.Printf("Successful: %d\n", totalSuccess)
+		fmt.Printf("Failed: %d\n", totalFailed)
+	}
+}
+
+// DeviceRegister registers a terminal UUID with an HTTPS adapter and stores the transport settings
+func DeviceRegister(cmd *cobra.Command, args []string) {
+	key, _ := cmd.Flags().GetString("key")
+	scheme, _ := cmd.Flags().GetString("scheme")
+	uuid, _ := cmd.Flags().GetString("uuid")
+
+	if key == "" {
+		fmt.Println("Error: --key is required (the key printed on the adapter label)")
+		return
+	}
+	if scheme != "http" && scheme != "https" {
+		fmt.Println("Error: --scheme must be http or https")
+		return
+	}
+
+	t, err := resolveSingleTarget(cmd)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	// Reuse the UUID already registered for this device, if any
+	if uuid == "" && t.MAC != "" {
+		if history, err := storage.GetDeviceHistory(t.MAC); err == nil && history.Transport != nil {
+			uuid = history.Transport.UUID
+		}
+	}
+	if uuid == "" {
+		if uuid, err = api.NewTerminalUUID(); err != nil {
+			fmt.Printf("Error: Failed to generate a terminal UUID: %v\n", err)
+			return
+		}
+	}
+
+	client := NewAPIClient(api.WithEndpoints(map[string]api.Endpoint{t.IP: {Scheme: scheme, UUID: uuid}}))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := client.RegisterTerminal(ctx, t.IP, key); err != nil {
+		fmt.Printf("%s: Error: Failed to register terminal: %v\n", t.label(), err)
+		return
+	}
+	fmt.Printf("%s: ✓ Terminal registered\n", t.label())
+
+	// Devices not in storage yet are recorded from basic_info so that later commands find the transport
+	mac := t.MAC
+	if mac == "" {
+		info, err := client.FetchBasicInfo(ctx, t.IP)
+		if err != nil {
+			fmt.Printf("Error: Failed to fetch basic_info, transport settings not saved (uuid %s): %v\n", uuid, err)
+			return
+		}
+		if history, err := findDeviceByMAC(info.HardwareAddr()); err == nil {
+			mac = history.MAC
+		} else {
+			mac = info.HardwareAddr()
+			device := search.Device{IP: t.IP, MAC: mac, Name: info.Name, Status: "online", BasicInfo: info.Values}
+			if err := storage.SaveDevices([]search.Device{device}); err != nil {
+				fmt.Printf("Error: Failed to save device: %v\n", err)
+				return
+			}
+		}
+	}
+
+	transport := storage.DeviceTransport{Scheme: scheme, UUID: uuid}
+	if err := storage.SetDeviceTransport(mac, transport); err != nil {
+		fmt.Printf("Error: Failed to save transport settings: %v\n", err)
+		return
+	}
+	fmt.Printf("Transport settings saved: %s, uuid %s\n", scheme, uuid)
+}
+
+// findDeviceByMAC returns the stored device with the given MAC, ignoring case and separators
+func findDeviceByMAC(mac string) (*storage.DeviceHistory, error) {
+	histories, err := storage.GetDeviceHistories()
+	if err != nil {
+		return nil, fmt.Errorf("error loading devices: %v", err)
+	}
+	want := normalizeMAC(mac)
+	for _, h := range histories {
+		if normalizeMAC(h.MAC) == want {
+			return h, nil
+		}
+	}
+	return nil, fmt.Errorf("device with MAC %s not found in storage", strings.TrimSpace(mac))
+}
+
+// normalizeMAC lowercases a MAC address and strips ":" and "-" separators
+func normalizeMAC(mac string) string {
+	return strings.NewReplacer(":", "", "-", "", " ", "").Replace(strings.ToLower(mac))
+}
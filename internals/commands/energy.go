@@ -0,0 +1,245 @@
+package commands
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/romaingallez/clim_cli/internals/api"
+	"github.com/romaingallez/clim_cli/internals/config"
+	"github.com/spf13/cobra"
+)
+
+// EnergyTotals holds consumption totals in kWh. Last7Days is the rolling window reported by
+// the adapters (today and the six days before), not the calendar week.
+type EnergyTotals struct {
+	Today     float64 `json:"today_kwh"`
+	Last7Days float64 `json:"last_7_days_kwh"`
+	Year      float64 `json:"year_kwh"`
+}
+
+// add adds the counters of d that were read
+func (t *EnergyTotals) add(d DeviceEnergy) {
+	t.Today += valueOr0(d.Today)
+	t.Last7Days += valueOr0(d.Last7Days)
+	t.Year += valueOr0(d.Year)
+}
+
+// Names of the energy counters, as used in DeviceEnergy.Errors
+const (
+	counterToday     = "today"
+	counterLast7Days = "last_7_days"
+	counterYear      = "year"
+)
+
+// DeviceEnergy is the energy report of a single device.
+// A counter the adapter could not report is nil and its error is kept in Errors;
+// older firmwares lack get_day_power_ex but still report the other counters.
+type DeviceEnergy struct {
+	Name      string            `json:"name"`
+	IP        string            `json:"ip"`
+	MAC       string            `json:"mac,omitempty"`
+	Group     string            `json:"group"`
+	Today     *float64          `json:"today_kwh,omitempty"`
+	Last7Days *float64          `json:"last_7_days_kwh,omitempty"`
+	Year      *float64          `json:"year_kwh,omitempty"`
+	Errors    map[string]string `json:"errors,omitempty"` // Keyed by counter: today, last_7_days or year
+}
+
+// read reports whether at least one counter was read
+func (d DeviceEnergy) read() bool {
+	return d.Today != nil || d.Last7Days != nil || d.Year != nil
+}
+
+// errorSummary joins the counter errors in counter order
+func (d DeviceEnergy) errorSummary() string {
+	var parts []string
+	for _, counter := range []string{counterToday, counterLast7Days, counterYear} {
+		if err, ok := d.Errors[counter]; ok {
+			parts = append(parts, counter+": "+err)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// GroupEnergy is the energy report of all devices sharing a grp_name
+type GroupEnergy struct {
+	Group   string `json:"group"`
+	Devices int    `json:"devices"`
+	EnergyTotals
+}
+
+// EnergyReport is the complete energy report written by the energy command
+type EnergyReport struct {
+	GeneratedAt time.Time      `json:"generated_at"`
+	Devices     []DeviceEnergy `json:"devices"`
+	Groups      []GroupEnergy  `json:"groups"`
+	Total       EnergyTotals   `json:"total"`
+}
+
+// EnergyClim reports energy consumption per device, per grp_name and for the whole fleet
+func EnergyClim(cmd *cobra.Command, args []string) {
+	format, _ := cmd.Flags().GetString("format")
+	if format != "table" && format != "csv" && format != "json" {
+		fmt.Println("Error: --format must be table, csv or json")
+		return
+	}
+
+	targets, err := resolveTargets(cmd)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	report := buildEnergyReport(NewAPIClient(), targets, config.GetSearchWorkers())
+
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Printf("Error encoding report: %v\n", err)
+			return
+		}
+		fmt.Println(string(data))
+	case "csv":
+		if err := writeEnergyCSV(report); err != nil {
+			fmt.Printf("Error writing CSV: %v\n", err)
+		}
+	default:
+		writeEnergyTable(report)
+	}
+}
+
+// buildEnergyReport fetches the power counters of every target and aggregates them
+func buildEnergyReport(client *api.Client, targets []target, workers int) EnergyReport {
+	report := EnergyReport{
+		GeneratedAt: time.Now(),
+		Devices:     make([]DeviceEnergy, len(targets)),
+	}
+
+	forEachTarget(targets, workers, func(i int, t target) {
+		report.Devices[i] = fetchDeviceEnergy(client, t)
+	})
+
+	groups := make(map[string]*GroupEnergy)
+	for _, d := range report.Devices {
+		if !d.read() {
+			continue
+		}
+		g, ok := groups[d.Group]
+		if !ok {
+			g = &GroupEnergy{Group: d.Group}
+			groups[d.Group] = g
+		}
+		g.Devices++
+		g.add(d)
+		report.Total.add(d)
+	}
+	for _, g := range groups {
+		report.Groups = append(report.Groups, *g)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		return report.Groups[i].Group < report.Groups[j].Group
+	})
+
+	return report
+}
+
+// fetchDeviceEnergy reads the day, last 7 days and year counters of a single device.
+// Each counter is read on its own: a failing endpoint only drops its own total.
+func fetchDeviceEnergy(client *api.Client, t target) DeviceEnergy {
+	d := DeviceEnergy{Name: t.Name, IP: t.IP, MAC: t.MAC, Group: t.Group}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	fail := func(counter string, err error) {
+		if d.Errors == nil {
+			d.Errors = make(map[string]string)
+		}
+		d.Errors[counter] = strings.TrimSpace(err.Error())
+	}
+	if day, err := client.FetchDayPower(ctx, t.IP); err != nil {
+		fail(counterToday, err)
+	} else {
+		d.Today = ptr(day.TotalKWh())
+	}
+	if week, err := client.FetchWeekPower(ctx, t.IP); err != nil {
+		fail(counterLast7Days, err)
+	} else {
+		d.Last7Days = ptr(week.TotalKWh())
+	}
+	if year, err := client.FetchYearPower(ctx, t.IP); err != nil {
+		fail(counterYear, err)
+	} else {
+		d.Year = ptr(year.TotalKWh())
+	}
+	return d
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+func valueOr0(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+// formatKWh formats a counter with the given precision, or missing when it was not read
+func formatKWh(v *float64, prec int, missing string) string {
+	if v == nil {
+		return missing
+	}
+	return strconv.FormatFloat(*v, 'f', prec, 64)
+}
+
+// writeEnergyTable prints the report as aligned tables
+func writeEnergyTable(report EnergyReport) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tIP\tGROUP\tTODAY kWh\tLAST 7 DAYS kWh\tYEAR kWh\tERR")
+	for _, d := range report.Devices {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", d.Name, d.IP, d.Group,
+			formatKWh(d.Today, 1, "-"), formatKWh(d.Last7Days, 1, "-"), formatKWh(d.Year, 1, "-"), d.errorSummary())
+	}
+	w.Flush()
+
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "GROUP\tDEVICES\tTODAY kWh\tLAST 7 DAYS kWh\tYEAR kWh")
+	for _, g := range report.Groups {
+		fmt.Fprintf(w, "%s\t%d\t%.1f\t%.1f\t%.1f\n", g.Group, g.Devices, g.Today, g.Last7Days, g.Year)
+	}
+	fmt.Fprintf(w, "TOTAL\t\t%.1f\t%.1f\t%.1f\n", report.Total.Today, report.Total.Last7Days, report.Total.Year)
+	w.Flush()
+}
+
+// writeEnergyCSV prints the report as CSV with a scope column (device, group or total)
+func writeEnergyCSV(report EnergyReport) error {
+	w := csv.NewWriter(os.Stdout)
+	kwh := func(v float64) string { return strconv.FormatFloat(v, 'f', 3, 64) }
+
+	rows := [][]string{{"scope", "name", "ip", "mac", "group", "today_kwh", "last_7_days_kwh", "year_kwh", "error"}}
+	for _, d := range report.Devices {
+		rows = append(rows, []string{"device", d.Name, d.IP, d.MAC, d.Group,
+			formatKWh(d.Today, 3, ""), formatKWh(d.Last7Days, 3, ""), formatKWh(d.Year, 3, ""), d.errorSummary()})
+	}
+	for _, g := range report.Groups {
+		rows = append(rows, []string{"group", "", "", "", g.Group, kwh(g.Today), kwh(g.Last7Days), kwh(g.Year), ""})
+	}
+	rows = append(rows, []string{"total", "", "", "", "", kwh(report.Total.Today), kwh(report.Total.Last7Days), kwh(report.Total.Year), ""})
+
+	if err := w.WriteAll(rows); err != nil {
+		return err
+	}
+	return w.Error()
+}
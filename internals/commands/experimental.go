@@ -0,0 +1,19 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/romaingallez/clim_cli/internals/api"
+	"github.com/spf13/cobra"
+)
+
+// requireExperimental reports whether --experimental was given, printing why it is needed otherwise
+func requireExperimental(cmd *cobra.Command) bool {
+	if experimental, _ := cmd.Flags().GetBool("experimental"); experimental {
+		return true
+	}
+	fmt.Printf("Error: '%s' writes to an experimental endpoint.\n", cmd.CommandPath())
+	fmt.Println(api.ExperimentalNote)
+	fmt.Println("Pass --experimental to run it anyway.")
+	return false
+}
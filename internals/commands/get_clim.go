@@ -29,10 +29,12 @@ func GetClim(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	client := NewAPIClient()
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	basicInfo, berr := api.FetchBasicInfo(ctx, ip)
-	controlInfo, cerr := api.FetchControlInfo(ctx, ip)
+	// Detecting the dialect routes the following requests of AirBase units to /skyfi
+	dialect, basicInfo, berr := client.DetectDialect(ctx, ip)
+	controlInfo, cerr := client.FetchControlInfo(ctx, ip)
 	if berr != nil {
 		fmt.Printf("Failed to fetch basic_info from %s: %v\n", ip, berr)
 	}
@@ -40,9 +42,15 @@ func GetClim(cmd *cobra.Command, args []string) {
 		fmt.Printf("Failed to fetch control_info from %s: %v\n", ip, cerr)
 	}
 	if berr == nil {
-		fmt.Printf("Basic info: %+v\n", basicInfo)
+		fmt.Printf("Basic info: %+v\n", basicInfo.Values)
+		if dialect != api.DialectStandard {
+			fmt.Printf("Dialect: %s\n", dialect)
+		}
 	}
 	if cerr == nil {
-		fmt.Printf("Control Info: %+v\n", controlInfo)
+		fmt.Printf("Control Info: %+v\n", controlInfo.Values)
+	}
+	if demand, derr := client.FetchDemandControl(ctx, ip); derr == nil {
+		fmt.Printf("Demand Control: %s\n", formatDemand(demand))
 	}
 }
@@ -0,0 +1,343 @@
+package commands
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/romaingallez/clim_cli/internals/storage"
+	"github.com/spf13/cobra"
+)
+
+// historyTimeLayout is how times are printed in history tables
+const historyTimeLayout = "2006-01-02 15:04:05"
+
+// HistoryEntry is one recorded change of a device
+type HistoryEntry struct {
+	Time     time.Time `json:"time"`
+	Device   string    `json:"device"`
+	IP       string    `json:"ip"`
+	MAC      string    `json:"mac"`
+	Group    string    `json:"group"`
+	Field    string    `json:"field"`
+	OldValue string    `json:"old_value"`
+	NewValue string    `json:"new_value"`
+}
+
+// SnapshotDiff is the field-by-field comparison of two snapshots of a device
+type SnapshotDiff struct {
+	Device  string                 `json:"device"`
+	MAC     string                 `json:"mac"`
+	From    time.Time              `json:"from"` // Time of the older snapshot
+	To      time.Time              `json:"to"`   // Time of the newer snapshot
+	Changes []storage.DeviceChange `json:"changes"`
+}
+
+// History prints the changes recorded for the stored devices, oldest first
+func History(cmd *cobra.Command, args []string) {
+	format, _ := cmd.Flags().GetString("format")
+	if format != "table" && format != "csv" && format != "json" {
+		fmt.Println("Error: --format must be table, csv or json")
+		return
+	}
+	since, _ := cmd.Flags().GetDuration("since")
+	fields, _ := cmd.Flags().GetStringSlice("field")
+	group, _ := cmd.Flags().GetString("group")
+
+	// Without a device argument, --since reads only the devices changed in the window
+	var histories []*storage.DeviceHistory
+	var err error
+	switch {
+	case len(args) > 0:
+		histories, err = storage.GetDeviceHistories()
+	case since > 0:
+		histories, err = storage.GetRecentChanges(since)
+	case group != "":
+		histories, err = storage.GetDevicesByGroup(group)
+	default:
+		histories, err = storage.GetDeviceHistories()
+	}
+	if err != nil {
+		fmt.Printf("Error: error loading devices: %v\n", err)
+		return
+	}
+	if len(args) > 0 {
+		history, err := findDevice(histories, args[0])
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		histories = []*storage.DeviceHistory{history}
+	}
+
+	var cutoff time.Time
+	if since > 0 {
+		cutoff = time.Now().Add(-since)
+	}
+	entries := historyEntries(histories, cutoff, group, fields)
+
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			fmt.Printf("Error encoding history: %v\n", err)
+			return
+		}
+		fmt.Println(string(data))
+	case "csv":
+		if err := writeHistoryCSV(entries); err != nil {
+			fmt.Printf("Error writing CSV: %v\n", err)
+		}
+	default:
+		if len(entries) == 0 {
+			fmt.Println("No changes recorded.")
+			return
+		}
+		writeHistoryTable(entries)
+	}
+}
+
+// HistoryDiff compares the snapshots of a device at two points in time field by field
+func HistoryDiff(cmd *cobra.Command, args []string) {
+	format, _ := cmd.Flags().GetString("format")
+	if format != "table" && format != "csv" && format != "json" {
+		fmt.Println("Error: --format must be table, csv or json")
+		return
+	}
+	now := time.Now()
+	t1, err := parseHistoryTime(args[1], now)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	t2, err := parseHistoryTime(args[2], now)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	histories, err := storage.GetDeviceHistories()
+	if err != nil {
+		fmt.Printf("Error: error loading devices: %v\n", err)
+		return
+	}
+	found, err := findDevice(histories, args[0])
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	history, err := storage.GetDeviceHistory(found.MAC)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	from, err := snapshotAt(history, t1)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	to, err := snapshotAt(history, t2)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	diff := SnapshotDiff{
+		Device:  history.Device.Name,
+		MAC:     history.MAC,
+		From:    from.LastSeenAt,
+		To:      to.LastSeenAt,
+		Changes: storage.DiffSnapshots(from, to),
+	}
+
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			fmt.Printf("Error encoding diff: %v\n", err)
+			return
+		}
+		fmt.Println(string(data))
+	case "csv":
+		if err := writeDiffCSV(diff); err != nil {
+			fmt.Printf("Error writing CSV: %v\n", err)
+		}
+	default:
+		fmt.Printf("%s (%s): snapshot of %s → snapshot of %s\n", diff.Device, diff.MAC,
+			diff.From.Local().Format(historyTimeLayout), diff.To.Local().Format(historyTimeLayout))
+		if len(diff.Changes) == 0 {
+			fmt.Println("No differences.")
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "FIELD\tOLD\tNEW")
+		for _, c := range diff.Changes {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", c.Field, orDash(c.OldValue), orDash(c.NewValue))
+		}
+		w.Flush()
+	}
+}
+
+// historyEntries flattens the changes of the histories made after cutoff, in the group and
+// matching one of fields (all when empty), sorted by time then device name
+func historyEntries(histories []*storage.DeviceHistory, cutoff time.Time, group string, fields []string) []HistoryEntry {
+	entries := []HistoryEntry{}
+	for _, h := range histories {
+		if group != "" && h.Device.BasicInfo["grp_name"] != group {
+			continue
+		}
+		for _, c := range h.Changes {
+			if !c.ChangedAt.After(cutoff) || !matchesField(fields, c.Field) {
+				continue
+			}
+			entries = append(entries, HistoryEntry{
+				Time:     c.ChangedAt,
+				Device:   h.Device.Name,
+				IP:       h.Device.IP,
+				MAC:      h.MAC,
+				Group:    h.Device.BasicInfo["grp_name"],
+				Field:    c.Field,
+				OldValue: c.OldValue,
+				NewValue: c.NewValue,
+			})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Time.Equal(entries[j].Time) {
+			return entries[i].Time.Before(entries[j].Time)
+		}
+		if entries[i].Device != entries[j].Device {
+			return entries[i].Device < entries[j].Device
+		}
+		return entries[i].Field < entries[j].Field
+	})
+	return entries
+}
+
+// matchesField reports whether field is one of fields or nested under one of them
+// ("control_info" matches "control_info.stemp")
+func matchesField(fields []string, field string) bool {
+	if len(fields) == 0 {
+		return true
+	}
+	for _, f := range fields {
+		if field == f || strings.HasPrefix(field, f+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// findDevice returns the device whose MAC, IP or name (case-insensitive) is sel
+func findDevice(histories []*storage.DeviceHistory, sel string) (*storage.DeviceHistory, error) {
+	sel = strings.TrimSpace(sel)
+	for _, h := range histories {
+		if normalizeMAC(h.MAC) == normalizeMAC(sel) || h.Device.IP == sel {
+			return h, nil
+		}
+	}
+	for _, h := range histories {
+		if strings.EqualFold(h.Device.Name, sel) {
+			return h, nil
+		}
+	}
+	return nil, fmt.Errorf("device %q not found in storage (use its MAC, IP or name)", sel)
+}
+
+// snapshotAt returns the last snapshot of the history taken at or before t
+func snapshotAt(history *storage.DeviceHistory, t time.Time) (storage.DeviceSnapshot, error) {
+	var best, first *storage.DeviceSnapshot
+	candidates := append(append([]storage.DeviceSnapshot{}, history.Snapshots...), history.Device)
+	for i := range candidates {
+		s := &candidates[i]
+		if first == nil || s.LastSeenAt.Before(first.LastSeenAt) {
+			first = s
+		}
+		if s.LastSeenAt.After(t) {
+			continue
+		}
+		if best == nil || s.LastSeenAt.After(best.LastSeenAt) {
+			best = s
+		}
+	}
+	if best == nil {
+		return storage.DeviceSnapshot{}, fmt.Errorf("no snapshot of %s at or before %s (the first one is from %s)",
+			history.Device.Name, t.Local().Format(historyTimeLayout), first.LastSeenAt.Local().Format(historyTimeLayout))
+	}
+	return *best, nil
+}
+
+// parseHistoryTime parses "now", a duration before now ("24h") or a date and time
+// ("2006-01-02", "2006-01-02 15:04", "2006-01-02T15:04:05" or RFC 3339), in local time
+func parseHistoryTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "now" {
+		return now, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			if layout == "2006-01-02" {
+				// A day means its end: the state the device was left in
+				t = t.Add(24*time.Hour - time.Nanosecond)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use now, a duration like 24h, or a date like 2006-01-02 15:04", s)
+}
+
+// writeHistoryTable prints the change log as an aligned table
+func writeHistoryTable(entries []HistoryEntry) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tDEVICE\tIP\tFIELD\tOLD\tNEW")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", e.Time.Local().Format(historyTimeLayout), e.Device, e.IP,
+			e.Field, orDash(e.OldValue), orDash(e.NewValue))
+	}
+	w.Flush()
+}
+
+// writeHistoryCSV prints the change log as CSV
+func writeHistoryCSV(entries []HistoryEntry) error {
+	w := csv.NewWriter(os.Stdout)
+	rows := [][]string{{"time", "device", "ip", "mac", "group", "field", "old_value", "new_value"}}
+	for _, e := range entries {
+		rows = append(rows, []string{e.Time.Format(time.RFC3339), e.Device, e.IP, e.MAC, e.Group, e.Field, e.OldValue, e.NewValue})
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return err
+	}
+	return w.Error()
+}
+
+// writeDiffCSV prints a snapshot diff as CSV
+func writeDiffCSV(diff SnapshotDiff) error {
+	w := csv.NewWriter(os.Stdout)
+	rows := [][]string{{"field", "old_value", "new_value"}}
+	for _, c := range diff.Changes {
+		rows = append(rows, []string{c.Field, c.OldValue, c.NewValue})
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return err
+	}
+	return w.Error()
+}
+
+// orDash returns "-" for empty values so that table columns stay aligned
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
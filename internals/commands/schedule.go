@@ -0,0 +1,363 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/romaingallez/clim_cli/internals/api"
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
+)
+
+// maxScheduleEntriesPerDay is the number of switch points the adapters store per day
+const maxScheduleEntriesPerDay = 4
+
+// ExitScheduleInvalid is the exit status of schedule device validate when the file
+// cannot be loaded or is not a valid program
+const ExitScheduleInvalid = 1
+
+// ScheduleExport writes the weekly program of a device as YAML
+func ScheduleExport(cmd *cobra.Command, args []string) {
+	client := NewAPIClient()
+	output, _ := cmd.Flags().GetString("output")
+
+	targets, err := resolveTargets(cmd)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if len(targets) != 1 {
+		fmt.Println("Error: export reads a single device, use --ip")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	program, err := client.FetchProgram(ctx, targets[0].IP)
+	if err != nil {
+		fmt.Printf("Error: Failed to fetch program from %s: %v\n", targets[0].label(), err)
+		return
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(scheduleFromProgram(*program, targets[0].label())); err != nil {
+		fmt.Printf("Error encoding schedule: %v\n", err)
+		return
+	}
+	data := buf.Bytes()
+
+	if output == "" || output == "-" {
+		fmt.Print(string(data))
+		return
+	}
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		fmt.Printf("Error writing %s: %v\n", output, err)
+		return
+	}
+	fmt.Printf("Program of %s exported to %s\n", targets[0].label(), output)
+}
+
+// ScheduleValidate checks a weekly program YAML file without contacting any device
+func ScheduleValidate(cmd *cobra.Command, args []string) {
+	schedule, err := loadSchedule(args[0])
+	if err != nil {
+		fmt.Printf("Error loading schedule: %v\n", err)
+		os.Exit(ExitScheduleInvalid)
+	}
+
+	if errs := validateSchedule(schedule, api.DefaultCapabilities()); len(errs) > 0 {
+		fmt.Printf("%s is invalid:\n", args[0])
+		for _, err := range errs {
+			fmt.Printf("  • %v\n", err)
+		}
+		os.Exit(ExitScheduleInvalid)
+	}
+	fmt.Printf("%s is valid\n", args[0])
+	for _, day := range api.Weekdays {
+		dayEntries := *schedule.Days.day(day)
+		entries := make([]string, 0, len(dayEntries))
+		for _, e := range dayEntries {
+			entries = append(entries, formatScheduleEntry(e))
+		}
+		fmt.Printf("  %s: %s\n", day, strings.Join(entries, ", "))
+	}
+}
+
+// SchedulePush writes a weekly program YAML file to the targeted devices
+func SchedulePush(cmd *cobra.Command, args []string) {
+	if !requireExperimental(cmd) {
+		return
+	}
+	days, err := getScheduleDays(cmd)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	schedule, err := loadSchedule(args[0])
+	if err != nil {
+		fmt.Printf("Error loading schedule: %v\n", err)
+		return
+	}
+
+	targets, err := resolveTargets(cmd)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	pushSchedule(NewAPIClient(), schedule, days, targets)
+}
+
+// ScheduleCopy copies the weekly program of one device to the targeted devices
+func ScheduleCopy(cmd *cobra.Command, args []string) {
+	if !requireExperimental(cmd) {
+		return
+	}
+	from, _ := cmd.Flags().GetString("from")
+	if from == "" {
+		fmt.Println("Error: --from is required")
+		return
+	}
+
+	days, err := getScheduleDays(cmd)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	targets, err := resolveTargets(cmd)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	targets = slices.DeleteFunc(targets, func(t target) bool { return t.IP == from })
+	if len(targets) == 0 {
+		fmt.Println("No target devices besides the source device.")
+		return
+	}
+
+	client := NewAPIClient()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	program, err := client.FetchProgram(ctx, from)
+	if err != nil {
+		fmt.Printf("Error: Failed to fetch program from %s: %v\n", from, err)
+		return
+	}
+
+	schedule := scheduleFromProgram(*program, from)
+	pushSchedule(client, &schedule, days, targets)
+}
+
+// pushSchedule validates the schedule against each device and writes it.
+// When days is not empty, only those days are replaced and the others are kept.
+func pushSchedule(client *api.Client, schedule *DeviceSchedule, days []string, targets []target) {
+	totalSuccess := 0
+	totalFailed := 0
+
+	for _, t := range targets {
+		fmt.Printf("\n  Device: %s\n", t.label())
+		if err := pushScheduleToDevice(client, schedule, days, t); err != nil {
+			fmt.Printf("    Error: %v\n", err)
+			totalFailed++
+			continue
+		}
+		fmt.Printf("    ✓ Program applied successfully\n")
+		totalSuccess++
+	}
+
+	fmt.Printf("\n=== Summary ===\n")
+	fmt.Printf("Total devices processed: %d\n", len(targets))
+	fmt.Printf("Successful: %d\n", totalSuccess)
+	fmt.Printf("Failed: %d\n", totalFailed)
+}
+
+// pushScheduleToDevice writes the schedule to a single device
+func pushScheduleToDevice(client *api.Client, schedule *DeviceSchedule, days []string, t target) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if errs := validateSchedule(schedule, lookupCapabilities(ctx, client, t.IP)); len(errs) > 0 {
+		return fmt.Errorf("unsupported by this unit: %v", errs[0])
+	}
+
+	program := schedule.toProgram()
+	if len(days) > 0 {
+		current, err := client.FetchProgram(ctx, t.IP)
+		if err != nil {
+			return fmt.Errorf("failed to fetch current program: %v", err)
+		}
+		for _, day := range days {
+			current.Days[day] = program.Days[day]
+		}
+		current.Enabled = program.Enabled
+		program = *current
+	}
+
+	if err := client.SetProgram(ctx, t.IP, program); err != nil {
+		return fmt.Errorf("failed to write program: %v", err)
+	}
+	return nil
+}
+
+// loadSchedule loads and parses a weekly program YAML file
+func loadSchedule(path string) (*DeviceSchedule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedule file: %w", err)
+	}
+
+	// Reject unknown keys so that misspelled days are not silently ignored
+	var schedule DeviceSchedule
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&schedule); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	return &schedule, nil
+}
+
+// validateSchedule checks every day and entry of the schedule against the capability set
+func validateSchedule(schedule *DeviceSchedule, caps api.Capabilities) []error {
+	var errs []error
+
+	for _, day := range api.Weekdays {
+		entries := *schedule.Days.day(day)
+		if len(entries) > maxScheduleEntriesPerDay {
+			errs = append(errs, fmt.Errorf("%s: %d entries, at most %d are supported", day, len(entries), maxScheduleEntriesPerDay))
+		}
+		previous := -1
+		for i, e := range entries {
+			minutes, err := parseScheduleTime(e.Time)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s entry %d: %v", day, i+1, err))
+				continue
+			}
+			if minutes <= previous {
+				errs = append(errs, fmt.Errorf("%s entry %d: %s is not after the previous entry", day, i+1, e.Time))
+			}
+			previous = minutes
+
+			switch e.Power {
+			case "0":
+				continue
+			case "1":
+			default:
+				errs = append(errs, fmt.Errorf("%s %s: power must be 0 or 1", day, e.Time))
+				continue
+			}
+			// Program entries carry no fan settings
+			if err := caps.ValidateSetpoint(e.Mode, e.Temp); err != nil {
+				errs = append(errs, fmt.Errorf("%s %s: %v", day, e.Time, err))
+			}
+		}
+	}
+
+	return errs
+}
+
+// parseScheduleTime parses "HH:MM" and returns minutes since midnight
+func parseScheduleTime(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("time %q must be HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// scheduleFromProgram converts the adapter program to its YAML document
+func scheduleFromProgram(program api.Program, source string) DeviceSchedule {
+	schedule := DeviceSchedule{
+		Enabled: program.Enabled,
+		Source:  source,
+	}
+	for _, day := range api.Weekdays {
+		entries := []ScheduleEntry{}
+		for _, e := range program.Days[day] {
+			hhmm := e.Time
+			if len(hhmm) == 4 {
+				hhmm = hhmm[:2] + ":" + hhmm[2:]
+			}
+			entries = append(entries, ScheduleEntry{Time: hhmm, Power: e.Power, Mode: e.Mode, Temp: e.Temp})
+		}
+		*schedule.Days.day(day) = entries
+	}
+	return schedule
+}
+
+// toProgram converts the YAML document to the adapter program
+func (s *DeviceSchedule) toProgram() api.Program {
+	program := api.Program{
+		Enabled: s.Enabled,
+		Days:    make(map[string][]api.ProgramEntry),
+	}
+	for _, day := range api.Weekdays {
+		for _, e := range *s.Days.day(day) {
+			entry := api.ProgramEntry{Time: strings.ReplaceAll(e.Time, ":", ""), Power: e.Power}
+			if e.Power == "1" {
+				entry.Mode = e.Mode
+				entry.Temp = e.Temp
+			}
+			program.Days[day] = append(program.Days[day], entry)
+		}
+	}
+	return program
+}
+
+// getScheduleDays parses the --days flag: a comma-separated list of days or ranges (e.g. "mon-fri,sun")
+func getScheduleDays(cmd *cobra.Command) ([]string, error) {
+	raw, _ := cmd.Flags().GetString("days")
+	if raw == "" {
+		return nil, nil
+	}
+
+	selected := make(map[int]bool)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		first, last, isRange := strings.Cut(part, "-")
+		if !isRange {
+			last = first
+		}
+		from := slices.Index(api.Weekdays, first)
+		to := slices.Index(api.Weekdays, last)
+		if from < 0 || to < 0 || from > to {
+			return nil, fmt.Errorf("invalid --days value %q (use e.g. mon-fri,sun)", part)
+		}
+		for i := from; i <= to; i++ {
+			selected[i] = true
+		}
+	}
+
+	indexes := make([]int, 0, len(selected))
+	for i := range selected {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	days := make([]string, 0, len(indexes))
+	for _, i := range indexes {
+		days = append(days, api.Weekdays[i])
+	}
+	return days, nil
+}
+
+// formatScheduleEntry renders an entry for display
+func formatScheduleEntry(e ScheduleEntry) string {
+	if e.Power != "1" {
+		return e.Time + " off"
+	}
+	if t, err := strconv.ParseFloat(e.Temp, 64); err == nil {
+		return fmt.Sprintf("%s on %s %.1f°C", e.Time, api.Mode(e.Mode), t)
+	}
+	return fmt.Sprintf("%s on %s", e.Time, api.Mode(e.Mode))
+}
@@ -0,0 +1,49 @@
+package commands
+
+// ScheduleEntry is a switch point of a device weekly program
+// Mode and Temp are ignored (and may be omitted) when Power is "0"
+type ScheduleEntry struct {
+	Time  string `yaml:"time"`           // Local time "HH:MM"
+	Power string `yaml:"power"`          // "0" or "1"
+	Mode  string `yaml:"mode,omitempty"` // "0"=AUTO, "1"=HEAT, "2"=DRY, "3"=FAN, "4"=COOL
+	Temp  string `yaml:"temp,omitempty"` // Temperature (e.g., "22.0")
+}
+
+// ScheduleWeek holds the entries of each day, in time order
+type ScheduleWeek struct {
+	Mon []ScheduleEntry `yaml:"mon"`
+	Tue []ScheduleEntry `yaml:"tue"`
+	Wed []ScheduleEntry `yaml:"wed"`
+	Thu []ScheduleEntry `yaml:"thu"`
+	Fri []ScheduleEntry `yaml:"fri"`
+	Sat []ScheduleEntry `yaml:"sat"`
+	Sun []ScheduleEntry `yaml:"sun"`
+}
+
+// DeviceSchedule represents the YAML document of a device weekly program
+type DeviceSchedule struct {
+	Enabled bool         `yaml:"enabled"`          // Whether the adapter runs the program
+	Source  string       `yaml:"source,omitempty"` // Device the program was exported from (informational)
+	Days    ScheduleWeek `yaml:"days"`
+}
+
+// day returns a pointer to the entries of the named day (mon..sun), or nil if unknown
+func (w *ScheduleWeek) day(name string) *[]ScheduleEntry {
+	switch name {
+	case "mon":
+		return &w.Mon
+	case "tue":
+		return &w.Tue
+	case "wed":
+		return &w.Wed
+	case "thu":
+		return &w.Thu
+	case "fri":
+		return &w.Fri
+	case "sat":
+		return &w.Sat
+	case "sun":
+		return &w.Sun
+	}
+	return nil
+}
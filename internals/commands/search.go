@@ -6,12 +6,12 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/romaingallez/clim_cli/internals/api"
 	"github.com/romaingallez/clim_cli/internals/config"
 	"github.com/romaingallez/clim_cli/internals/search"
 	"github.com/romaingallez/clim_cli/internals/storage"
 	"github.com/romaingallez/clim_cli/internals/tui"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 func SearchClim(cmd *cobra.Command, args []string) {
@@ -20,12 +20,39 @@ func SearchClim(cmd *cobra.Command, args []string) {
 	timeout, _ := cmd.Flags().GetInt("timeout")
 	workers, _ := cmd.Flags().GetInt("workers")
 	tuiMode, _ := cmd.Flags().GetBool("tui")
+	methodName, _ := cmd.Flags().GetString("method")
+	cidrs, _ := cmd.Flags().GetStringSlice("cidr")
+	sweepTimeout, _ := cmd.Flags().GetDuration("sweep-timeout")
+	allVendors, _ := cmd.Flags().GetBool("all-vendors")
+	probe, _ := cmd.Flags().GetBool("probe")
 
-	fmt.Printf("Searching for climate devices on interface: %s\n", ifaceName)
+	rules := search.MatchRulesFromConfig(config.GetSearchMatch())
+	rules.AllVendors = allVendors
+	if probe {
+		rules.Probe = true
+	}
+
+	method := search.DefaultMethod()
+	if methodName != "" {
+		method = search.Method(methodName)
+	} else if len(cidrs) > 0 {
+		method = search.MethodSweep
+	} else if allVendors {
+		method = search.MethodARP
+	}
+
+	fmt.Printf("Searching for climate devices on interface: %s (method: %s)\n", ifaceName, method)
 	fmt.Printf("Timeout: %d seconds, Workers: %d\n", timeout, workers)
 
-	// Use fuzzy search for "*murata*" pattern and save AC manufacturer MACs to config
-	devices, err := search.FuzzySearchDevices(ifaceName, timeout, workers, "murata")
+	devices, err := search.DiscoverDevices(NewAPIClient(), method, search.Options{
+		Iface:   ifaceName,
+		Timeout: timeout,
+		Workers: workers,
+		CIDRs:   cidrs,
+		Match:   rules,
+		// Most probed hosts never answer: probe once with a short timeout
+		Probe: NewAPIClient(api.WithTimeout(sweepTimeout), api.WithRetry(api.RetryPolicy{MaxAttempts: 1})),
+	})
 	if err != nil {
 		msg := err.Error()
 		if strings.Contains(msg, "arp-scan is not installed") {
@@ -43,7 +70,7 @@ func SearchClim(cmd *cobra.Command, args []string) {
 	}
 
 	if len(devices) == 0 {
-		fmt.Println("No climate devices found matching 'murata' pattern")
+		fmt.Printf("No climate devices found (method: %s)\n", method)
 		return
 	}
 
@@ -66,12 +93,11 @@ func SearchClim(cmd *cobra.Command, args []string) {
 			fmt.Printf("\nSelected %d device(s):\n", len(selectedDevices))
 			for i, device := range selectedDevices {
 				fmt.Printf("%d. %s (%s)\n", i+1, device.Device.Name, device.Device.IP)
-				viper.Set("ip", device.Device.IP)
-				viper.Set("name", device.Device.Name)
 			}
 
 			// Persist the last selected device to the default config file
-			if err := config.SaveConfig(); err != nil {
+			last := selectedDevices[len(selectedDevices)-1].Device
+			if err := config.SaveDefaultDevice(last.IP, last.Name); err != nil {
 				log.Printf("Warning: Failed to save selected device to config: %v", err)
 			} else {
 				cfgPath := filepath.Join(config.GetConfigDir(), config.ConfigFileName+"."+config.ConfigFileType)
@@ -84,7 +110,7 @@ func SearchClim(cmd *cobra.Command, args []string) {
 	}
 
 	// Traditional text output
-	fmt.Printf("\nFound %d climate device(s) matching 'murata' pattern:\n", len(devices))
+	fmt.Printf("\nFound %d climate device(s) (method: %s):\n", len(devices), method)
 	for i, device := range devices {
 		fmt.Printf("%d. IP: %s, Status: %s, Name: %s, MAC: %s\n", i+1, device.IP, device.Status, device.Name, device.MAC)
 
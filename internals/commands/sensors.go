@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/romaingallez/clim_cli/internals/api"
+	"github.com/romaingallez/clim_cli/internals/config"
+	"github.com/romaingallez/clim_cli/internals/storage"
+	"github.com/spf13/cobra"
+)
+
+// sensorResult holds the sensor reading of a single target
+type sensorResult struct {
+	target target
+	info   *api.SensorInfo
+	err    error
+}
+
+// SensorsClim reads room/outdoor sensors from one device, a group or all stored devices
+func SensorsClim(cmd *cobra.Command, args []string) {
+	targets, err := resolveTargets(cmd)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	results := fetchSensors(NewAPIClient(), targets, config.GetSearchWorkers())
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tIP\tROOM\tHUMIDITY\tOUTDOOR\tCOMPRESSOR\tERR")
+	readings := make(map[string]map[string]string)
+	for _, r := range results {
+		if r.err != nil {
+			fmt.Fprintf(w, "%s\t%s\t-\t-\t-\t-\t%v\n", r.target.Name, r.target.IP, r.err)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.target.Name, r.target.IP,
+			formatReading(r.info.RoomTemp, "°C"),
+			formatReading(r.info.RoomHumidity, "%"),
+			formatReading(r.info.OutdoorTemp, "°C"),
+			formatReading(r.info.CompressorFreq, "Hz"),
+			r.info.Error,
+		)
+		if r.target.MAC != "" {
+			readings[r.target.MAC] = r.info.Values
+		}
+	}
+	w.Flush()
+
+	// Keep the last reading next to the stored device
+	if len(readings) > 0 {
+		if err := storage.SaveSensorReadings(readings); err != nil {
+			log.Printf("Warning: Failed to save sensor readings to storage: %v", err)
+		}
+	}
+}
+
+// fetchSensors reads sensors of all targets in parallel with a worker cap, preserving order
+func fetchSensors(client *api.Client, targets []target, workers int) []sensorResult {
+	results := make([]sensorResult, len(targets))
+	forEachTarget(targets, workers, func(i int, t target) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		info, err := client.FetchSensorInfo(ctx, t.IP)
+		results[i] = sensorResult{target: t, info: info, err: err}
+	})
+	return results
+}
+
+// formatReading appends a unit to a sensor value, keeping "-" for unavailable readings
+func formatReading(value, unit string) string {
+	if value == "" || value == "-" || value == "--" {
+		return "-"
+	}
+	return value + unit
+}
@@ -2,12 +2,14 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strconv"
+	"os"
 	"time"
 
 	"github.com/romaingallez/clim_cli/internals/api"
 	"github.com/romaingallez/clim_cli/internals/config"
+	"github.com/romaingallez/clim_cli/internals/storage"
 	"github.com/spf13/cobra"
 )
 
@@ -25,36 +27,45 @@ func SetClim(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	client := NewAPIClient()
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	// Fetch current settings from device
 	fmt.Printf("Fetching current settings from %s...\n", climConfig.IP)
-	currentControlInfo, err := api.FetchControlInfo(ctx, climConfig.IP)
+	currentClim := api.Clim{IP: climConfig.IP}
+	currentControlInfo, err := client.FetchControlInfo(ctx, climConfig.IP)
 	fetchedCurrent := err == nil
 	if err != nil {
 		fmt.Printf("Warning: Failed to fetch current settings: %v\n", err)
 		fmt.Println("Proceeding with provided values only...")
-		currentControlInfo = make(map[string]string)
+	} else {
+		// Build current Clim from device response
+		currentClim = currentControlInfo.Clim(climConfig.IP)
 	}
 
-	// Build current Clim from device response
-	currentClim := buildClimFromControlInfo(climConfig.IP, currentControlInfo)
-
 	// Build new Clim: use flag values if provided, otherwise keep current device values or use config defaults
 	newClim := buildNewClimFromFlags(cmd, currentClim, climConfig, fetchedCurrent)
 
-	// Validate configuration
-	if err := validateClimConfig(&config.Config{
-		IP:      newClim.IP,
-		Power:   newClim.Power,
-		Mode:    newClim.Mode,
-		Temp:    newClim.Temp,
-		FanDir:  newClim.FanDir,
-		FanRate: newClim.FanRate,
-	}); err != nil {
+	// Parse requested special modes
+	specialFlags, _ := cmd.Flags().GetStringSlice("special")
+	specials, err := parseSpecialModeSettings(specialFlags)
+	if err != nil {
 		fmt.Println(err.Error())
-		return
+		os.Exit(ExitSpecialModeFailed)
+	}
+
+	// Validate configuration against what the unit supports
+	caps := lookupCapabilities(ctx, client, newClim.IP)
+	if err := caps.Validate(newClim); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(ExitSetFailed)
+	}
+	for _, special := range specials {
+		if err := caps.ValidateSpecialMode(special); err != nil {
+			fmt.Println(err.Error())
+			os.Exit(ExitSpecialModeFailed)
+		}
 	}
 
 	// Display current settings
@@ -67,11 +78,35 @@ func SetClim(cmd *cobra.Command, args []string) {
 	displayChanges(currentClim, newClim)
 
 	// Apply new settings
-	if err := api.SetClim(ctx, newClim); err != nil {
-		fmt.Printf("\nFailed to apply settings to %s: %v\n", newClim.IP, err)
-		return
+	if err := client.SetClim(ctx, newClim); err != nil {
+		fmt.Printf("\nFailed to apply settings to %s: %s\n", newClim.IP, describeSetError(err, newClim))
+		os.Exit(ExitSetFailed)
 	}
 	fmt.Printf("\nSettings applied to %s\n", newClim.IP)
+
+	// Apply special modes once the base settings are accepted
+	specialFailed := false
+	for _, special := range specials {
+		if err := client.SetSpecialMode(ctx, newClim.IP, special); err != nil {
+			fmt.Printf("Failed to set special mode %s on %s: %v\n", special, newClim.IP, err)
+			specialFailed = true
+			continue
+		}
+		fmt.Printf("Special mode %s applied to %s\n", special, newClim.IP)
+	}
+
+	// Read the settings back when --verify is set
+	if timeout := getVerifyTimeout(cmd); timeout > 0 {
+		fmt.Printf("Verifying %s...\n", newClim.IP)
+		if err := verifyClim(client, newClim, timeout); err != nil {
+			printVerifyError(err, "")
+			os.Exit(ExitVerifyFailed)
+		}
+		fmt.Println("✓ Settings verified")
+	}
+	if specialFailed {
+		os.Exit(ExitSpecialModeFailed)
+	}
 }
 
 // getClimConfigFromFlags builds a config from flags, using defaults where not provided
@@ -124,20 +159,6 @@ func getClimConfigFromFlags(cmd *cobra.Command) (*config.Config, error) {
 	return cfg, nil
 }
 
-// buildClimFromControlInfo builds a Clim struct from the API control info response
-func buildClimFromControlInfo(ip string, controlInfo map[string]string) api.Clim {
-	clim := api.Clim{
-		IP:      ip,
-		Power:   controlInfo["pow"],
-		Mode:    controlInfo["mode"],
-		Temp:    controlInfo["stemp"],
-		Shum:    controlInfo["shum"],
-		FanRate: controlInfo["f_rate"],
-		FanDir:  controlInfo["f_dir"],
-	}
-	return clim
-}
-
 // buildNewClimFromFlags builds a new Clim struct using flag values if provided, otherwise current device values or config defaults
 func buildNewClimFromFlags(cmd *cobra.Command, currentClim api.Clim, climConfig *config.Config, fetchedCurrent bool) api.Clim {
 	// Start with current device values if we fetched them, otherwise use config defaults
@@ -198,34 +219,11 @@ func displayClimSettings(clim api.Clim) {
 		powerStatus = "ON"
 	}
 
-	modeNames := map[string]string{
-		"0": "AUTO",
-		"1": "HEAT",
-		"2": "DRY",
-		"3": "FAN",
-		"4": "COOL",
-	}
-	modeName := modeNames[clim.Mode]
-	if modeName == "" {
-		modeName = clim.Mode
-	}
-
-	fanDirNames := map[string]string{
-		"0": "All wings stopped",
-		"1": "Vertical wings motion",
-		"2": "Horizontal wings motion",
-		"3": "Vertical and horizontal wings motion",
-	}
-	fanDirName := fanDirNames[clim.FanDir]
-	if fanDirName == "" {
-		fanDirName = clim.FanDir
-	}
-
 	fmt.Printf("  Power:    %s\n", powerStatus)
-	fmt.Printf("  Mode:     %s (%s)\n", modeName, clim.Mode)
+	fmt.Printf("  Mode:     %s (%s)\n", api.Mode(clim.Mode), clim.Mode)
 	fmt.Printf("  Temp:     %s°C\n", clim.Temp)
 	fmt.Printf("  Fan Rate: %s\n", clim.FanRate)
-	fmt.Printf("  Fan Dir:  %s (%s)\n", fanDirName, clim.FanDir)
+	fmt.Printf("  Fan Dir:  %s (%s)\n", api.FanDir(clim.FanDir), clim.FanDir)
 }
 
 // displayChanges shows what settings are being changed
@@ -257,21 +255,7 @@ func displayChanges(current, new api.Clim) {
 	}
 
 	if current.FanDir != new.FanDir {
-		fanDirNames := map[string]string{
-			"0": "All wings stopped",
-			"1": "Vertical wings motion",
-			"2": "Horizontal wings motion",
-			"3": "Vertical and horizontal wings motion",
-		}
-		oldFanDirName := fanDirNames[current.FanDir]
-		if oldFanDirName == "" {
-			oldFanDirName = current.FanDir
-		}
-		newFanDirName := fanDirNames[new.FanDir]
-		if newFanDirName == "" {
-			newFanDirName = new.FanDir
-		}
-		changes = append(changes, fmt.Sprintf("Fan Dir: %s (%s) → %s (%s)", oldFanDirName, current.FanDir, newFanDirName, new.FanDir))
+		changes = append(changes, fmt.Sprintf("Fan Dir: %s (%s) → %s (%s)", api.FanDir(current.FanDir), current.FanDir, api.FanDir(new.FanDir), new.FanDir))
 	}
 
 	if len(changes) > 0 {
@@ -284,42 +268,39 @@ func displayChanges(current, new api.Clim) {
 	}
 }
 
-// validateClimConfig validates the climate configuration values
-func validateClimConfig(cfg *config.Config) error {
-	// Validate power
-	if cfg.Power != "0" && cfg.Power != "1" {
-		return fmt.Errorf("power must be 0 or 1")
+// lookupCapabilities returns the capability set stored for the device.
+// Devices missing from storage are asked for their model info, falling back to the defaults.
+func lookupCapabilities(ctx context.Context, client *api.Client, ip string) api.Capabilities {
+	if history, err := storage.FindDeviceByIP(ip); err == nil && history.Device.Capabilities != nil {
+		return *history.Device.Capabilities
 	}
-
-	// Validate mode
-	if cfg.Mode != "0" && cfg.Mode != "1" && cfg.Mode != "2" && cfg.Mode != "3" && cfg.Mode != "4" {
-		return fmt.Errorf("mode must be between 0 and 4")
-	}
-
-	// Validate temperature
-	tempNum, err := strconv.ParseFloat(cfg.Temp, 64)
-	if err != nil {
-		return fmt.Errorf("error converting temperature to float: %w", err)
-	}
-	if tempNum < 16.0 || tempNum > 30.0 {
-		return fmt.Errorf("temperature must be between 16.0 and 30.0")
+	if info, err := client.FetchModelInfo(ctx, ip); err == nil {
+		return info.Capabilities()
 	}
+	return api.DefaultCapabilities()
+}
 
-	// Validate fan rate
-	if cfg.FanRate != "A" {
-		fanNum, err := strconv.Atoi(cfg.FanRate)
+// parseSpecialModeSettings parses --special values such as "powerful" or "econo=off"
+func parseSpecialModeSettings(values []string) ([]api.SpecialModeSetting, error) {
+	settings := make([]api.SpecialModeSetting, 0, len(values))
+	for _, v := range values {
+		setting, err := api.ParseSpecialModeSetting(v)
 		if err != nil {
-			return fmt.Errorf("fan_rate must be between 3 and 7 or A")
-		}
-		if fanNum < 3 || fanNum > 7 {
-			return fmt.Errorf("fan_rate must be between 3 and 7 or A")
+			return nil, err
 		}
+		settings = append(settings, setting)
 	}
+	return settings, nil
+}
 
-	// Validate fan direction
-	if cfg.FanDir != "0" && cfg.FanDir != "1" && cfg.FanDir != "2" && cfg.FanDir != "3" {
-		return fmt.Errorf("fan_dir must be 0 (all wings stopped), 1 (vertical wings motion), 2 (horizontal wings motion), or 3 (vertical and horizontal wings motion)")
+// describeSetError explains a SetClim failure, naming the rejected values when the adapter refused them
+func describeSetError(err error, clim api.Clim) string {
+	switch {
+	case errors.Is(err, api.ErrParamNG):
+		return fmt.Sprintf("device rejected the parameters (pow=%s mode=%s stemp=%s shum=%s f_rate=%s f_dir=%s): %v",
+			clim.Power, clim.Mode, clim.Temp, clim.Shum, clim.FanRate, clim.FanDir, err)
+	case errors.Is(err, api.ErrAdvNG):
+		return fmt.Sprintf("device refused the change in its current state: %v", err)
 	}
-
-	return nil
+	return err.Error()
 }
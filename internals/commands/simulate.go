@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/romaingallez/clim_cli/internals/api"
+	"github.com/romaingallez/clim_cli/internals/search"
+	"github.com/romaingallez/clim_cli/internals/simulator"
+	"github.com/romaingallez/clim_cli/internals/storage"
+	"github.com/spf13/cobra"
+)
+
+// Simulate starts fake adapters on local ports and serves them until interrupted
+func Simulate(cmd *cobra.Command, args []string) {
+	count, _ := cmd.Flags().GetInt("count")
+	host, _ := cmd.Flags().GetString("listen")
+	port, _ := cmd.Flags().GetInt("port")
+	latency, _ := cmd.Flags().GetDuration("latency")
+	failRate, _ := cmd.Flags().GetFloat64("fail-rate")
+	register, _ := cmd.Flags().GetBool("register")
+
+	if failRate < 0 || failRate > 1 {
+		fmt.Println("Error: --fail-rate must be between 0 and 1")
+		return
+	}
+
+	sim, err := simulator.Start(host, port, count, simulator.Options{Latency: latency, FailRate: failRate})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer sim.Close()
+
+	fmt.Printf("Simulating %d adapter(s) (latency: %s, fail rate: %.0f%%):\n", count, latency, failRate*100)
+	for i, adapter := range sim.Adapters {
+		fmt.Printf("  %s: %s (MAC: %s)\n", adapter.Name, sim.Addrs[i], adapter.MAC)
+	}
+
+	// Register through the regular sweep discovery so storage holds the same data as for real units
+	if register {
+		devices, err := search.DiscoverDevices(NewAPIClient(), search.MethodSweep, search.Options{
+			Timeout: 5,
+			Workers: count,
+			CIDRs:   sim.Addrs,
+			Probe:   NewAPIClient(api.WithTimeout(search.SweepTimeout)),
+		})
+		if err != nil {
+			fmt.Printf("Error: Failed to discover simulated adapters: %v\n", err)
+		} else if err := storage.SaveDevices(devices); err != nil {
+			fmt.Printf("Error: Failed to save simulated adapters to storage: %v\n", err)
+		} else {
+			fmt.Printf("\nRegistered %d of %d adapter(s) in storage (group %q)\n", len(devices), count, "Simulated")
+		}
+	}
+
+	fmt.Printf("\nTry: clim_cli get --ip %s\n", sim.Addrs[0])
+	fmt.Println("Press Ctrl+C to stop.")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+	fmt.Println("\nStopping simulated adapters")
+}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/romaingallez/clim_cli/internals/config"
+	"github.com/romaingallez/clim_cli/internals/storage"
+	"github.com/spf13/cobra"
+)
+
+// StorageImportJSON copies a devices.json file (the default one without argument) into the bbolt database
+func StorageImportJSON(cmd *cobra.Command, args []string) {
+	src, err := storage.GetStoragePath()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if len(args) > 0 {
+		src = args[0]
+	}
+	dst, _ := cmd.Flags().GetString("db")
+	if dst == "" {
+		if dst, err = storage.GetDatabasePath(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+	}
+
+	store, err := storage.OpenBoltStore(dst)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer store.Close()
+
+	n, err := storage.ImportJSON(src, store)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("Imported %d device(s) from %s into %s\n", n, src, dst)
+	if config.GetStorageBackend() != storage.BackendBolt {
+		fmt.Printf("Set 'storage.backend: %s' in the config file to use it.\n", storage.BackendBolt)
+	}
+}
+
+// StorageMigrate upgrades the configured storage to the current schema version
+func StorageMigrate(cmd *cobra.Command, args []string) {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	report, err := storage.MigrateStore(dryRun)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Storage backend: %s\n", config.GetStorageBackend())
+	if len(report.Steps) == 0 {
+		fmt.Printf("Already at schema version %d (%d device(s)), nothing to migrate\n", report.To, report.Devices)
+		return
+	}
+
+	fmt.Printf("Schema version %d → %d (%d device(s)):\n", report.From, report.To, report.Devices)
+	for _, step := range report.Steps {
+		fmt.Printf("  v%d: %s (%d device(s) changed)\n", step.Version, step.Description, step.Changed)
+	}
+	if dryRun {
+		fmt.Println("\nDry run: nothing was written.")
+		return
+	}
+	fmt.Println("\n✓ Storage migrated")
+}
+
+// StoragePrune drops the archived snapshots beyond the retention settings, folding them into daily summaries
+func StoragePrune(cmd *cobra.Command, args []string) {
+	policy := storage.RetentionFromConfig()
+	if cmd.Flags().Changed("max-snapshots") {
+		policy.MaxSnapshots, _ = cmd.Flags().GetInt("max-snapshots")
+	}
+	if cmd.Flags().Changed("max-age") {
+		policy.MaxAge, _ = cmd.Flags().GetDuration("max-age")
+	}
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	if !policy.Limited() {
+		fmt.Println("No retention limit set: use --max-snapshots/--max-age or storage.retention in the config file.")
+		return
+	}
+
+	report, err := storage.PruneStore(policy, dryRun)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Storage backend: %s\n", config.GetStorageBackend())
+	fmt.Printf("Retention: max %d snapshots, max age %s per device\n", policy.MaxSnapshots, policy.MaxAge)
+	if report.Dropped == 0 {
+		fmt.Printf("Nothing to prune (%d snapshot(s) kept)\n", report.Kept)
+		return
+	}
+	fmt.Printf("%d snapshot(s) of %d device(s) dropped, %d kept, %d daily summary(ies) written\n",
+		report.Dropped, report.Devices, report.Kept, report.Summaries)
+	if dryRun {
+		fmt.Println("\nDry run: nothing was written.")
+		return
+	}
+	fmt.Println("\n✓ Storage pruned")
+}
@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/romaingallez/clim_cli/internals/config"
+	"github.com/romaingallez/clim_cli/internals/storage"
+	"github.com/spf13/cobra"
+)
+
+// target is a device selected from the command line
+type target struct {
+	IP    string
+	Name  string
+	MAC   string // Empty when the IP is not in storage
+	Group string
+}
+
+// label returns a human readable identifier for the target
+func (t target) label() string {
+	if t.Name != "" {
+		return fmt.Sprintf("%s (%s)", t.Name, t.IP)
+	}
+	return t.IP
+}
+
+// resolveTargets selects devices from the --all, --group and --ip flags.
+// Flags are checked in that order; --ip falls back to the configured default IP.
+// Groups are matched with filterDevicesByGroupName, like the batch commands.
+func resolveTargets(cmd *cobra.Command) ([]target, error) {
+	all, _ := cmd.Flags().GetBool("all")
+	groupName, _ := cmd.Flags().GetString("group")
+
+	if all || groupName != "" {
+		histories, err := storage.GetDeviceHistories()
+		if err != nil {
+			return nil, fmt.Errorf("error loading devices: %v", err)
+		}
+		if len(histories) == 0 {
+			return nil, fmt.Errorf("no devices found in storage. Run 'clim_cli search' first")
+		}
+		if !all {
+			histories = filterDevicesByGroupName(histories, groupName)
+		}
+		if len(histories) == 0 {
+			return nil, fmt.Errorf("no devices found with grp_name: %s", groupName)
+		}
+		targets := make([]target, 0, len(histories))
+		for _, h := range histories {
+			targets = append(targets, targetFromHistory(h))
+		}
+		return targets, nil
+	}
+
+	ip, _ := cmd.Flags().GetString("ip")
+	if ip == "" {
+		ip = config.GetDefaultIP()
+	}
+	if ip == "" {
+		return nil, fmt.Errorf("no IP configured. Use --ip, --group or --all, or run 'clim_cli search --tui' or 'clim_cli browse' to select a device")
+	}
+
+	// Enrich with stored name/MAC when the device is known
+	if h, err := storage.FindDeviceByIP(ip); err == nil {
+		return []target{targetFromHistory(h)}, nil
+	}
+	return []target{{IP: ip}}, nil
+}
+
+// targetFromHistory builds a target from a stored device
+func targetFromHistory(h *storage.DeviceHistory) target {
+	return target{
+		IP:    h.Device.IP,
+		Name:  h.Device.Name,
+		MAC:   h.MAC,
+		Group: h.Device.BasicInfo["grp_name"],
+	}
+}
+
+// forEachTarget calls fn for every target with at most workers calls in flight
+func forEachTarget(targets []target, workers int, fn func(i int, t target)) {
+	if workers < 1 {
+		workers = 1
+	}
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, t target) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i, t)
+		}(i, t)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/romaingallez/clim_cli/internals/api"
+	"github.com/spf13/cobra"
+)
+
+// maxTimerDelay is the longest delay accepted by the adapters
+const maxTimerDelay = 24 * time.Hour
+
+// TimerGet prints the on/off timer of the targeted devices
+func TimerGet(cmd *cobra.Command, args []string) {
+	client := NewAPIClient()
+	targets, err := resolveTargets(cmd)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	for _, t := range targets {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		timer, err := client.FetchTimer(ctx, t.IP)
+		cancel()
+		if err != nil {
+			fmt.Printf("%s: Error: Failed to fetch timer: %v\n", t.label(), err)
+			continue
+		}
+		fmt.Printf("%s: on %s, off %s\n", t.label(), formatTimer(timer.OnEnabled, timer.OnDelay), formatTimer(timer.OffEnabled, timer.OffDelay))
+	}
+}
+
+// TimerSet programs on and/or off delays on the targeted devices
+func TimerSet(cmd *cobra.Command, args []string) {
+	onDelay, _ := cmd.Flags().GetDuration("on")
+	offDelay, _ := cmd.Flags().GetDuration("off")
+	onSet := cmd.Flags().Changed("on")
+	offSet := cmd.Flags().Changed("off")
+
+	if !onSet && !offSet {
+		fmt.Println("Error: At least one of --on or --off must be provided")
+		return
+	}
+	for _, d := range []struct {
+		name  string
+		set   bool
+		delay time.Duration
+	}{{"--on", onSet, onDelay}, {"--off", offSet, offDelay}} {
+		if d.set && (d.delay < time.Minute || d.delay > maxTimerDelay) {
+			fmt.Printf("Error: %s must be between 1m and %s\n", d.name, maxTimerDelay)
+			return
+		}
+	}
+
+	targets, err := resolveTargets(cmd)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	applyTimer(NewAPIClient(), targets, func(timer *api.Timer) {
+		if onSet {
+			timer.OnEnabled = true
+			timer.OnDelay = onDelay.Round(time.Minute)
+		}
+		if offSet {
+			timer.OffEnabled = true
+			timer.OffDelay = offDelay.Round(time.Minute)
+		}
+	})
+}
+
+// TimerClear disables the on/off timers of the targeted devices
+func TimerClear(cmd *cobra.Command, args []string) {
+	onlyOn, _ := cmd.Flags().GetBool("on")
+	onlyOff, _ := cmd.Flags().GetBool("off")
+
+	targets, err := resolveTargets(cmd)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	// Without --on/--off both timers are cleared
+	applyTimer(NewAPIClient(), targets, func(timer *api.Timer) {
+		if onlyOn || !onlyOff {
+			timer.OnEnabled = false
+			timer.OnDelay = 0
+		}
+		if onlyOff || !onlyOn {
+			timer.OffEnabled = false
+			timer.OffDelay = 0
+		}
+	})
+}
+
+// applyTimer reads each device timer, lets update modify it and writes it back
+func applyTimer(client *api.Client, targets []target, update func(timer *api.Timer)) {
+	totalSuccess := 0
+	totalFailed := 0
+
+	for _, t := range targets {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		timer, err := client.FetchTimer(ctx, t.IP)
+		if err != nil {
+			cancel()
+			fmt.Printf("%s: Error: Failed to fetch timer: %v\n", t.label(), err)
+			totalFailed++
+			continue
+		}
+		update(timer)
+		err = client.SetTimer(ctx, t.IP, *timer)
+		cancel()
+		if err != nil {
+			fmt.Printf("%s: Error: Failed to set timer: %v\n", t.label(), err)
+			totalFailed++
+			continue
+		}
+		fmt.Printf("%s: ✓ on %s, off %s\n", t.label(), formatTimer(timer.OnEnabled, timer.OnDelay), formatTimer(timer.OffEnabled, timer.OffDelay))
+		totalSuccess++
+	}
+
+	if len(targets) > 1 {
+		fmt.Printf("\n=== Summary ===\n")
+		fmt.Printf("Successful: %d\n", totalSuccess)
+		fmt.Printf("Failed: %d\n", totalFailed)
+	}
+}
+
+// formatTimer renders a timer delay, or "disabled"
+func formatTimer(enabled bool, delay time.Duration) string {
+	if !enabled {
+		return "disabled"
+	}
+	return "in " + delay.String()
+}
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/romaingallez/clim_cli/internals/api"
+	"github.com/spf13/cobra"
+)
+
+// ExitSetFailed is the exit status of set when the settings are invalid for the unit,
+// or the adapter rejects them (e.g. ret=PARAM NG) or cannot be reached
+const ExitSetFailed = 2
+
+// ExitVerifyFailed is the exit status of set and batch when --verify finds a unit
+// that did not take the requested settings
+const ExitVerifyFailed = 3
+
+// ExitSpecialModeFailed is the exit status of set when a requested special mode is
+// rejected or fails to apply
+const ExitSpecialModeFailed = 4
+
+// getVerifyTimeout returns the --verify-timeout deadline when --verify is set, 0 otherwise
+func getVerifyTimeout(cmd *cobra.Command) time.Duration {
+	verify, _ := cmd.Flags().GetBool("verify")
+	if !verify {
+		return 0
+	}
+	timeout, _ := cmd.Flags().GetDuration("verify-timeout")
+	return timeout
+}
+
+// verifyClim polls the unit until it reports the requested settings or the timeout passes
+func verifyClim(client *api.Client, clim api.Clim, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return client.VerifyClim(ctx, clim, api.DefaultVerifyInterval)
+}
+
+// printVerifyError prints a verification failure with one line per mismatching field
+func printVerifyError(err error, indent string) {
+	var verifyErr *api.VerifyError
+	if !errors.As(err, &verifyErr) {
+		fmt.Printf("%sError: Verification failed: %v\n", indent, err)
+		return
+	}
+	fmt.Printf("%sError: Unit did not take the settings:\n", indent)
+	for _, m := range verifyErr.Mismatches {
+		fmt.Printf("%s  • %s\n", indent, m)
+	}
+}
@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/romaingallez/clim_cli/internals/api"
+	"github.com/spf13/cobra"
+)
+
+// ZonesList prints the ducted zones of the targeted AirBase units
+func ZonesList(cmd *cobra.Command, args []string) {
+	targets, err := resolveTargets(cmd)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	client := NewAPIClient()
+	for _, t := range targets {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		zones, err := fetchZones(ctx, client, t.IP)
+		cancel()
+		if err != nil {
+			fmt.Printf("%s: Error: Failed to fetch zones: %v\n", t.label(), err)
+			continue
+		}
+		fmt.Printf("%s:\n", t.label())
+		for i, name := range zones.Names {
+			fmt.Printf("  %d. %-20s %s\n", i+1, name, dispOnOff(zones.On[i]))
+		}
+	}
+}
+
+// ZonesOn turns the given zones on
+func ZonesOn(cmd *cobra.Command, args []string) {
+	setZones(cmd, args, true)
+}
+
+// ZonesOff turns the given zones off
+func ZonesOff(cmd *cobra.Command, args []string) {
+	setZones(cmd, args, false)
+}
+
+// setZones switches the zones given by name or number on every target, keeping the other zones as they are
+func setZones(cmd *cobra.Command, args []string, on bool) {
+	targets, err := resolveTargets(cmd)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	client := NewAPIClient()
+	totalSuccess := 0
+	totalFailed := 0
+
+	for _, t := range targets {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err := applyZones(ctx, client, t.IP, args, on)
+		cancel()
+		if err != nil {
+			fmt.Printf("%s: Error: %v\n", t.label(), err)
+			totalFailed++
+			continue
+		}
+		fmt.Printf("%s: ✓ %s turned %s\n", t.label(), strings.Join(args, ", "), dispOnOff(on))
+		totalSuccess++
+	}
+
+	if len(targets) > 1 {
+		fmt.Printf("\n=== Summary ===\n")
+		fmt.Printf("Total devices processed: %d\n", len(targets))
+		fmt.Printf("Successful: %d\n", totalSuccess)
+		fmt.Printf("Failed: %d\n", totalFailed)
+	}
+}
+
+// applyZones reads the zone settings of a device, switches the selected zones and writes them back
+func applyZones(ctx context.Context, client *api.Client, ip string, selectors []string, on bool) error {
+	zones, err := fetchZones(ctx, client, ip)
+	if err != nil {
+		return fmt.Errorf("failed to fetch zones: %v", err)
+	}
+	for _, sel := range selectors {
+		i, err := findZone(zones.Names, sel)
+		if err != nil {
+			return err
+		}
+		zones.On[i] = on
+	}
+	if err := client.SetZones(ctx, ip, *zones); err != nil {
+		return fmt.Errorf("failed to set zones: %v", err)
+	}
+	return nil
+}
+
+// fetchZones reads the zone settings, detecting the dialect first for devices not in storage
+func fetchZones(ctx context.Context, client *api.Client, ip string) (*api.Zones, error) {
+	if client.Dialect(ip) == "" {
+		if _, _, err := client.DetectDialect(ctx, ip); err != nil {
+			return nil, err
+		}
+	}
+	return client.FetchZones(ctx, ip)
+}
+
+// findZone returns the index of the zone given by its 1-based number or its name (case-insensitive)
+func findZone(names []string, sel string) (int, error) {
+	if n, err := strconv.Atoi(sel); err == nil {
+		if n < 1 || n > len(names) {
+			return 0, fmt.Errorf("zone %d out of range (1-%d)", n, len(names))
+		}
+		return n - 1, nil
+	}
+	for i, name := range names {
+		if strings.EqualFold(strings.TrimSpace(name), strings.TrimSpace(sel)) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("no zone named %q", sel)
+}
+
+// dispOnOff renders a boolean state
+func dispOnOff(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
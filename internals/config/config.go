@@ -1,12 +1,19 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/romaingallez/clim_cli/internals/safefile"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
 )
 
 const (
@@ -17,20 +24,52 @@ const (
 
 // Config represents the application configuration
 type Config struct {
-	IP      string       `mapstructure:"ip" yaml:"ip"`
-	Name    string       `mapstructure:"name" yaml:"name"`
-	Power   string       `mapstructure:"power" yaml:"power"`
-	Mode    string       `mapstructure:"mode" yaml:"mode"`
-	Temp    string       `mapstructure:"temp" yaml:"temp"`
-	FanDir  string       `mapstructure:"fan_dir" yaml:"fan_dir"`
-	FanRate string       `mapstructure:"fan_rate" yaml:"fan_rate"`
-	Search  SearchConfig `mapstructure:"search" yaml:"search"`
+	IP      string        `mapstructure:"ip" yaml:"ip"`
+	Name    string        `mapstructure:"name" yaml:"name"`
+	Power   string        `mapstructure:"power" yaml:"power"`
+	Mode    string        `mapstructure:"mode" yaml:"mode"`
+	Temp    string        `mapstructure:"temp" yaml:"temp"`
+	FanDir  string        `mapstructure:"fan_dir" yaml:"fan_dir"`
+	FanRate string        `mapstructure:"fan_rate" yaml:"fan_rate"`
+	Search  SearchConfig  `mapstructure:"search" yaml:"search"`
+	API     APIConfig     `mapstructure:"api" yaml:"api"`
+	Storage StorageConfig `mapstructure:"storage" yaml:"storage"`
 }
 
 // SearchConfig represents search-related configuration
 type SearchConfig struct {
-	Timeout int `mapstructure:"timeout" yaml:"timeout"`
-	Workers int `mapstructure:"workers" yaml:"workers"`
+	Timeout int         `mapstructure:"timeout" yaml:"timeout"`
+	Workers int         `mapstructure:"workers" yaml:"workers"`
+	Match   MatchConfig `mapstructure:"match" yaml:"match"`
+}
+
+// MatchConfig represents the rules deciding which arp-scan hosts are climate devices
+type MatchConfig struct {
+	OUIPrefixes []string `mapstructure:"oui_prefixes" yaml:"oui_prefixes"` // MAC prefixes, e.g. "a0:c9:a0"
+	Vendors     []string `mapstructure:"vendors" yaml:"vendors"`           // Case-insensitive substrings of the arp-scan vendor
+	Probe       bool     `mapstructure:"probe" yaml:"probe"`               // Keep unmatched hosts whose basic_info reports type=aircon
+}
+
+// APIConfig represents the adapter HTTP client configuration
+type APIConfig struct {
+	Timeout        time.Duration `mapstructure:"timeout" yaml:"timeout"`                   // Per-attempt request timeout
+	MaxAttempts    int           `mapstructure:"max_attempts" yaml:"max_attempts"`         // Attempts per read request (1 disables retries)
+	RetryBaseDelay time.Duration `mapstructure:"retry_base_delay" yaml:"retry_base_delay"` // Backoff before the first retry
+	RetryMaxDelay  time.Duration `mapstructure:"retry_max_delay" yaml:"retry_max_delay"`   // Upper bound of a single backoff
+	RetryBudget    int           `mapstructure:"retry_budget" yaml:"retry_budget"`         // Retries allowed per command run (0 = unlimited)
+}
+
+// StorageConfig represents the device storage configuration
+type StorageConfig struct {
+	Backend   string          `mapstructure:"backend" yaml:"backend"` // "json" (devices.json) or "bolt" (devices.db)
+	Retention RetentionConfig `mapstructure:"retention" yaml:"retention"`
+}
+
+// RetentionConfig represents how many archived snapshots are kept per device
+type RetentionConfig struct {
+	MaxSnapshots int           `mapstructure:"max_snapshots" yaml:"max_snapshots"`   // Archived snapshots kept per device (0 = unlimited)
+	MaxAge       time.Duration `mapstructure:"max_age" yaml:"max_age"`               // Archived snapshots older than this are summarized (0 = keep forever)
+	OnlyOnChange bool          `mapstructure:"only_on_change" yaml:"only_on_change"` // Archive a snapshot only when a change was detected
 }
 
 var configDir string
@@ -59,7 +98,7 @@ func InitConfig() error {
 	setDefaults()
 
 	// Read config file (ignore error if file doesn't exist)
-	if err := viper.ReadInConfig(); err != nil {
+	if err := readConfig(ConfigFileName); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			return fmt.Errorf("failed to read config file: %w", err)
 		}
@@ -108,13 +147,30 @@ func setDefaults() {
 	viper.SetDefault("fan_rate", "A")
 	viper.SetDefault("search.timeout", 5)
 	viper.SetDefault("search.workers", 10)
+	viper.SetDefault("search.match.oui_prefixes", []string{})
+	viper.SetDefault("search.match.vendors", []string{"murata"})
+	viper.SetDefault("search.match.probe", false)
+	viper.SetDefault("api.timeout", "4s")
+	viper.SetDefault("api.max_attempts", 3)
+	viper.SetDefault("api.retry_base_delay", "200ms")
+	viper.SetDefault("api.retry_max_delay", "2s")
+	viper.SetDefault("api.retry_budget", 50)
+	viper.SetDefault("storage.backend", "json")
+	viper.SetDefault("storage.retention.max_snapshots", 0)
+	viper.SetDefault("storage.retention.max_age", "0s")
+	viper.SetDefault("storage.retention.only_on_change", false)
 }
 
 // SaveConfig saves the current configuration to file
 func SaveConfig() error {
-	// Try writing to an existing config file
-	if err := viper.WriteConfig(); err != nil {
-		// If no existing config, create the default one
+	return saveKeys(nil)
+}
+
+// saveKeys writes keys to the existing config file, or creates the default one.
+// A nil keys writes every setting.
+func saveKeys(keys []string) error {
+	path := viper.ConfigFileUsed()
+	if path == "" {
 		dir := configDir
 		if dir == "" {
 			var derr error
@@ -126,29 +182,126 @@ func SaveConfig() error {
 		if mkerr := ensureConfigDir(dir); mkerr != nil {
 			return fmt.Errorf("failed to create config directory: %w", mkerr)
 		}
-		path := filepath.Join(dir, ConfigFileName+"."+ConfigFileType)
-		if werr := viper.WriteConfigAs(path); werr != nil {
-			return fmt.Errorf("failed to write config file: %w", werr)
-		}
+		path = filepath.Join(dir, ConfigFileName+"."+ConfigFileType)
+	}
+	if werr := writeConfig(path, keys); werr != nil {
+		return fmt.Errorf("failed to write config file: %w", werr)
 	}
 	return nil
 }
 
 // SaveConfigAs saves the current configuration to a specific file
 func SaveConfigAs(filename string) error {
-	return viper.WriteConfigAs(filepath.Join(configDir, filename+"."+ConfigFileType))
+	return writeConfig(filepath.Join(configDir, filename+"."+ConfigFileType), nil)
 }
 
 // LoadConfig loads configuration from a specific file
 func LoadConfig(filename string) error {
 	viper.SetConfigName(filename)
-	return viper.ReadInConfig()
+	return readConfig(filename)
+}
+
+// writeConfig writes the current value of keys to the config file at path, atomically and
+// under the file lock so that concurrent clim_cli processes never see a partial file.
+// The file is re-read under the lock and only keys are replaced, so that settings written
+// by another process since this one started are kept. A nil keys writes every setting.
+func writeConfig(path string, keys []string) error {
+	lock, err := safefile.LockFile(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	settings := viper.AllSettings()
+	if keys != nil {
+		if settings, err = readSettings(path); err != nil {
+			return err
+		}
+		for _, key := range keys {
+			setNested(settings, strings.Split(key, "."), viper.Get(key))
+		}
+	}
+	data, err := yaml.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	return safefile.WriteFile(path, data, 0644)
+}
+
+// readSettings returns the settings stored in the config file at path, empty when it does not exist
+func readSettings(path string) (map[string]any, error) {
+	settings := map[string]any{}
+	data, _, err := safefile.ReadFile(path, func(data []byte) error {
+		return yaml.Unmarshal(data, &map[string]any{})
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = map[string]any{}
+	}
+	return settings, nil
+}
+
+// setNested sets settings[path[0]][path[1]]... to value, creating the intermediate maps
+func setNested(settings map[string]any, path []string, value any) {
+	for _, k := range path[:len(path)-1] {
+		sub, ok := settings[k].(map[string]any)
+		if !ok {
+			sub = map[string]any{}
+			settings[k] = sub
+		}
+		settings = sub
+	}
+	settings[path[len(path)-1]] = value
+}
+
+// readConfig reads the named config file under a shared lock.
+// A corrupt file is replaced by its .bak copy when that one is valid.
+func readConfig(name string) error {
+	lock, err := safefile.RLockFile(filepath.Join(configDir, name+"."+ConfigFileType))
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	err = viper.ReadInConfig()
+	if err == nil {
+		return nil
+	}
+	path := viper.ConfigFileUsed()
+	if _, ok := err.(viper.ConfigFileNotFoundError); ok || path == "" {
+		return err
+	}
+	data, fromBackup, rerr := safefile.ReadFile(path, func(data []byte) error {
+		var settings map[string]any
+		return yaml.Unmarshal(data, &settings)
+	})
+	if rerr != nil || !fromBackup {
+		return err
+	}
+	log.Printf("Warning: %s is corrupt (%v), using %s%s", path, err, path, safefile.BackupSuffix)
+	viper.SetConfigFile(path)
+	return viper.ReadConfig(bytes.NewReader(data))
 }
 
 // SaveACManufacturerMACs persists the AC manufacturer MAC addresses to config
 func SaveACManufacturerMACs(macs []string) error {
 	viper.Set("ac_manufacturer_macs", macs)
-	return SaveConfig()
+	return saveKeys([]string{"ac_manufacturer_macs"})
+}
+
+// SaveDefaultDevice persists the device used when --ip is not given
+func SaveDefaultDevice(ip, name string) error {
+	viper.Set("ip", ip)
+	viper.Set("name", name)
+	return saveKeys([]string{"ip", "name"})
 }
 
 // GetACManufacturerMACs retrieves stored AC manufacturer MAC addresses
@@ -201,6 +354,40 @@ func GetSearchWorkers() int {
 	return viper.GetInt("search.workers")
 }
 
+// GetSearchMatch returns the search match rules
+func GetSearchMatch() MatchConfig {
+	return MatchConfig{
+		OUIPrefixes: viper.GetStringSlice("search.match.oui_prefixes"),
+		Vendors:     viper.GetStringSlice("search.match.vendors"),
+		Probe:       viper.GetBool("search.match.probe"),
+	}
+}
+
+// GetAPIConfig returns the adapter HTTP client configuration
+func GetAPIConfig() APIConfig {
+	return APIConfig{
+		Timeout:        viper.GetDuration("api.timeout"),
+		MaxAttempts:    viper.GetInt("api.max_attempts"),
+		RetryBaseDelay: viper.GetDuration("api.retry_base_delay"),
+		RetryMaxDelay:  viper.GetDuration("api.retry_max_delay"),
+		RetryBudget:    viper.GetInt("api.retry_budget"),
+	}
+}
+
+// GetStorageBackend returns the device storage backend
+func GetStorageBackend() string {
+	return viper.GetString("storage.backend")
+}
+
+// GetRetentionConfig returns the snapshot retention settings
+func GetRetentionConfig() RetentionConfig {
+	return RetentionConfig{
+		MaxSnapshots: viper.GetInt("storage.retention.max_snapshots"),
+		MaxAge:       viper.GetDuration("storage.retention.max_age"),
+		OnlyOnChange: viper.GetBool("storage.retention.only_on_change"),
+	}
+}
+
 // GetConfig returns the current configuration as a Config struct
 func GetConfig() (*Config, error) {
 	var cfg Config
@@ -223,6 +410,26 @@ func SetConfig(cfg *Config) error {
 		"search": map[string]any{
 			"timeout": cfg.Search.Timeout,
 			"workers": cfg.Search.Workers,
+			"match": map[string]any{
+				"oui_prefixes": cfg.Search.Match.OUIPrefixes,
+				"vendors":      cfg.Search.Match.Vendors,
+				"probe":        cfg.Search.Match.Probe,
+			},
+		},
+		"api": map[string]any{
+			"timeout":          cfg.API.Timeout.String(),
+			"max_attempts":     cfg.API.MaxAttempts,
+			"retry_base_delay": cfg.API.RetryBaseDelay.String(),
+			"retry_max_delay":  cfg.API.RetryMaxDelay.String(),
+			"retry_budget":     cfg.API.RetryBudget,
+		},
+		"storage": map[string]any{
+			"backend": cfg.Storage.Backend,
+			"retention": map[string]any{
+				"max_snapshots":  cfg.Storage.Retention.MaxSnapshots,
+				"max_age":        cfg.Storage.Retention.MaxAge.String(),
+				"only_on_change": cfg.Storage.Retention.OnlyOnChange,
+			},
 		},
 	})
 }
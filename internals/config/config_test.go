@@ -0,0 +1,47 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+func TestSaveKeepsSettingsWrittenByOtherProcesses(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CLIM_CLI_CONFIG_DIR", dir)
+	path := filepath.Join(dir, ConfigFileName+"."+ConfigFileType)
+	if err := os.WriteFile(path, []byte("ip: 10.0.0.1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := InitConfig(); err != nil {
+		t.Fatalf("InitConfig: %v", err)
+	}
+
+	// Another process changes the file after this one read it
+	if err := os.WriteFile(path, []byte("ip: 10.0.0.2\nsearch:\n  workers: 42\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveACManufacturerMACs([]string{"a0:c9:a0:00:00:01"}); err != nil {
+		t.Fatalf("SaveACManufacturerMACs: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var settings map[string]any
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		t.Fatal(err)
+	}
+	if settings["ip"] != "10.0.0.2" {
+		t.Errorf("ip = %v, want the value written by the other process", settings["ip"])
+	}
+	if search, _ := settings["search"].(map[string]any); search["workers"] != 42 {
+		t.Errorf("search = %v, want workers 42", settings["search"])
+	}
+	if macs, _ := settings["ac_manufacturer_macs"].([]any); len(macs) != 1 {
+		t.Errorf("ac_manufacturer_macs = %v", settings["ac_manufacturer_macs"])
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !unix && !windows
+
+package safefile
+
+import "os"
+
+// Platforms without file locking rely on the atomic rename alone
+
+func lockFile(f *os.File, exclusive bool) error {
+	return nil
+}
+
+func unlockFile(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,24 @@
+//go:build windows
+
+package safefile
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// allBytes locks the whole file, whatever its size
+const allBytes = ^uint32(0)
+
+func lockFile(f *os.File, exclusive bool) error {
+	var flags uint32
+	if exclusive {
+		flags = windows.LOCKFILE_EXCLUSIVE_LOCK
+	}
+	return windows.LockFileEx(windows.Handle(f.Fd()), flags, 0, allBytes, allBytes, new(windows.Overlapped))
+}
+
+func unlockFile(f *os.File) error {
+	return windows.UnlockFileEx(windows.Handle(f.Fd()), 0, allBytes, allBytes, new(windows.Overlapped))
+}
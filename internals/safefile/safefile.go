@@ -0,0 +1,136 @@
+// Package safefile writes files that other clim_cli processes may read or write at the
+// same time: advisory locks across processes, atomic replacement and a .bak fallback.
+package safefile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// BackupSuffix is appended to the path of the previous version of a file
+const BackupSuffix = ".bak"
+
+// Lock is an advisory lock held on the "<path>.lock" file
+type Lock struct {
+	f *os.File
+}
+
+// LockFile takes an exclusive lock for path, waiting for other processes to release theirs
+func LockFile(path string) (*Lock, error) {
+	return lock(path, true)
+}
+
+// RLockFile takes a shared lock for path, waiting for an exclusive lock to be released
+func RLockFile(path string) (*Lock, error) {
+	return lock(path, false)
+}
+
+func lock(path string, exclusive bool) (*Lock, error) {
+	f, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	if err := lockFile(f, exclusive); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	return &Lock{f: f}, nil
+}
+
+// Unlock releases the lock
+func (l *Lock) Unlock() error {
+	if err := unlockFile(l.f); err != nil {
+		l.f.Close()
+		return err
+	}
+	return l.f.Close()
+}
+
+// WriteFile replaces path with data atomically: data goes to a temporary file in the same
+// directory which is synced and renamed over path. The previous version is kept as
+// "<path>.bak". Callers sharing the file should hold LockFile.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+
+	if err := backup(path); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ReadFile reads path and checks it with valid. When the file is unreadable or invalid
+// (e.g. truncated by a crash) the "<path>.bak" copy is tried; fromBackup reports its use.
+// A missing path is returned as is, without trying the backup.
+func ReadFile(path string, valid func([]byte) error) (data []byte, fromBackup bool, err error) {
+	data, err = os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, err
+	}
+	if err == nil {
+		if err = valid(data); err == nil {
+			return data, false, nil
+		}
+	}
+
+	bak, berr := os.ReadFile(path + BackupSuffix)
+	if berr != nil || valid(bak) != nil {
+		return nil, false, fmt.Errorf("%s is corrupt and has no valid backup: %w", path, err)
+	}
+	return bak, true, nil
+}
+
+// backup keeps the current version of path as "<path>.bak": a hard link when the
+// filesystem allows it, a copy otherwise. A missing path is not an error.
+func backup(path string) error {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	bak := path + BackupSuffix
+	if err := os.Remove(bak); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.Link(path, bak); err == nil {
+		return nil
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(bak, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
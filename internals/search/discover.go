@@ -0,0 +1,69 @@
+package search
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/romaingallez/clim_cli/internals/api"
+)
+
+// Method selects the discovery backend
+type Method string
+
+const (
+	MethodUDP   Method = "udp"   // Adapter discovery broadcast, no privileges needed
+	MethodARP   Method = "arp"   // arp-scan filtered by the match rules, needs root
+	MethodSweep Method = "sweep" // basic_info probe of every host of the given CIDRs, works across routed subnets
+)
+
+// Options holds the settings of a discovery run
+type Options struct {
+	Iface   string
+	Timeout int // Seconds; UDP listen time and per-device info fetches
+	Workers int
+	CIDRs   []string    // Prefixes probed by MethodSweep
+	Match   MatchRules  // Rules filtering the MethodARP hosts
+	Probe   *api.Client // Client used by MethodSweep and match probes; nil means the discovery client
+}
+
+// DefaultMethod returns arp when arp-scan is installed and we run as root, udp otherwise
+func DefaultMethod() Method {
+	if _, err := exec.LookPath("arp-scan"); err != nil {
+		return MethodUDP
+	}
+	if os.Geteuid() != 0 {
+		return MethodUDP
+	}
+	return MethodARP
+}
+
+// DiscoverDevices finds devices with the given method and fetches their info
+func DiscoverDevices(client *api.Client, method Method, opts Options) ([]Device, error) {
+	probe := opts.Probe
+	if probe == nil {
+		probe = client
+	}
+
+	var devices []Device
+	var err error
+	switch method {
+	case MethodARP:
+		devices, err = SearchDevices(opts.Iface, opts.Timeout, opts.Workers)
+		if err == nil {
+			devices = MatchDevices(probe, devices, opts.Match, opts.Workers)
+		}
+	case MethodUDP:
+		devices, err = DiscoverUDP(opts.Iface, opts.Timeout)
+	case MethodSweep:
+		devices, err = SweepDevices(probe, opts.CIDRs, opts.Workers)
+	default:
+		return nil, fmt.Errorf("unknown search method %q", method)
+	}
+	if err != nil {
+		return nil, err
+	}
+	enrichDevices(client, devices, opts.Timeout, opts.Workers)
+	saveACManufacturerMACs(devices)
+	return devices, nil
+}
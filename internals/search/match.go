@@ -0,0 +1,95 @@
+package search
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/romaingallez/clim_cli/internals/api"
+	"github.com/romaingallez/clim_cli/internals/config"
+)
+
+// MatchRules decide which arp-scan hosts are kept as climate devices
+type MatchRules struct {
+	OUIPrefixes []string // MAC prefixes, any separator or case
+	Vendors     []string // Case-insensitive substrings of the arp-scan vendor
+	Probe       bool     // Keep unmatched hosts whose basic_info reports type=aircon
+	AllVendors  bool     // Ignore OUI and vendor rules: every host is probed
+}
+
+// MatchRulesFromConfig returns the rules configured under search.match
+func MatchRulesFromConfig(cfg config.MatchConfig) MatchRules {
+	return MatchRules{
+		OUIPrefixes: cfg.OUIPrefixes,
+		Vendors:     cfg.Vendors,
+		Probe:       cfg.Probe,
+	}
+}
+
+// Matches reports whether the host matches an OUI prefix or a vendor substring
+func (r MatchRules) Matches(d Device) bool {
+	if r.AllVendors {
+		return false
+	}
+	mac := normalizeHex(d.MAC)
+	for _, prefix := range r.OUIPrefixes {
+		if p := normalizeHex(prefix); p != "" && strings.HasPrefix(mac, p) {
+			return true
+		}
+	}
+	vendor := strings.ToLower(d.Name)
+	for _, v := range r.Vendors {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" && strings.Contains(vendor, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchDevices keeps the hosts matching the rules. When probing, the other hosts are asked
+// for basic_info with at most workers concurrent requests and kept if they report type=aircon.
+func MatchDevices(probe *api.Client, devices []Device, rules MatchRules, workers int) []Device {
+	keep := make([]bool, len(devices))
+	var candidates []int
+	for i, d := range devices {
+		if rules.Matches(d) {
+			keep[i] = true
+		} else if rules.Probe || rules.AllVendors {
+			candidates = append(candidates, i)
+		}
+	}
+
+	if len(candidates) > 0 {
+		if workers < 1 {
+			workers = 1
+		}
+		log.Printf("Probing %d unmatched hosts for basic_info type=aircon", len(candidates))
+		sem := make(chan struct{}, workers)
+		var wg sync.WaitGroup
+		for _, i := range candidates {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				_, info, err := probe.DetectDialect(context.Background(), devices[i].IP)
+				keep[i] = err == nil && info.Type == "aircon"
+			}()
+		}
+		wg.Wait()
+	}
+
+	var matched []Device
+	for i, d := range devices {
+		if keep[i] {
+			matched = append(matched, d)
+		}
+	}
+	return matched
+}
+
+// normalizeHex lowercases a MAC or OUI prefix and strips its separators
+func normalizeHex(s string) string {
+	return strings.ToLower(strings.NewReplacer(":", "", "-", "", ".", "").Replace(strings.TrimSpace(s)))
+}
@@ -23,8 +23,13 @@ type Device struct {
 	Name        string
 	Model       string
 	MAC         string
+	Dialect     api.Dialect // Empty when basic_info could not be read
 	BasicInfo   map[string]string
 	ControlInfo map[string]string
+	SensorInfo  map[string]string
+	ModelInfo   map[string]string
+	// Capabilities is nil when the unit did not answer get_model_info
+	Capabilities *api.Capabilities
 }
 
 // SearchDevices searches for climate devices in the specified IP range using arp-scan
@@ -166,72 +171,71 @@ func parseArpScanOutput(output string) ([]Device, error) {
 	return devices, nil
 }
 
-// FuzzySearchDevices searches for devices matching a fuzzy pattern and saves AC manufacturer MACs to config
-func FuzzySearchDevices(ifaceString string, timeout int, workers int, pattern string) ([]Device, error) {
-	// First, get all devices
-	devices, err := SearchDevices(ifaceString, timeout, workers)
-	if err != nil {
-		return nil, err
+// saveACManufacturerMACs records the MACs of the discovered devices in the config,
+// whatever the discovery method. A search that found nothing keeps the previous list.
+func saveACManufacturerMACs(devices []Device) {
+	acMACs := make([]string, 0, len(devices))
+	for _, d := range devices {
+		if d.MAC != "" {
+			acMACs = append(acMACs, d.MAC)
+		}
+	}
+	if len(acMACs) == 0 {
+		return
+	}
+	if err := config.SaveACManufacturerMACs(acMACs); err != nil {
+		log.Printf("Warning: Failed to save AC manufacturer MACs to config: %v", err)
 	}
+}
 
-	// Filter devices using fuzzy search
-	filteredDevices := fuzzyFilter(devices, pattern)
+// enrichDevices fetches basic, control, model and sensor info of every device in parallel
+// with a worker cap, detecting the adapter dialect on the way
+func enrichDevices(client *api.Client, devices []Device, timeout int, workers int) {
+	if workers < 1 {
+		workers = 1
+	}
 
 	// Parallel fetch basic/control info per device with worker cap
 	sem := make(chan struct{}, workers)
 	var wg sync.WaitGroup
-	for i := range filteredDevices {
+	for i := range devices {
 		i := i
 		wg.Add(1)
 		sem <- struct{}{}
 		go func() {
 			defer wg.Done()
 			defer func() { <-sem }()
-			log.Printf("Getting clim info for device %s", filteredDevices[i].IP)
+			log.Printf("Getting clim info for device %s", devices[i].IP)
 			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
 			defer cancel()
-			basicInfo, berr := api.FetchBasicInfo(ctx, filteredDevices[i].IP)
+			dialect, basicInfo, berr := client.DetectDialect(ctx, devices[i].IP)
 			if berr == nil {
-				filteredDevices[i].BasicInfo = basicInfo
-				if name, ok := basicInfo["name"]; ok && name != "" {
-					filteredDevices[i].Name = name
+				devices[i].Dialect = dialect
+				devices[i].BasicInfo = basicInfo.Values
+				if basicInfo.Name != "" {
+					devices[i].Name = basicInfo.Name
 				}
 			}
-			controlInfo, cerr := api.FetchControlInfo(ctx, filteredDevices[i].IP)
+			controlInfo, cerr := client.FetchControlInfo(ctx, devices[i].IP)
 			if cerr == nil {
-				filteredDevices[i].ControlInfo = controlInfo
+				devices[i].ControlInfo = controlInfo.Values
+			}
+			modelInfo, merr := client.FetchModelInfo(ctx, devices[i].IP)
+			if merr == nil {
+				caps := devices[i].Dialect.Capabilities(modelInfo.Capabilities())
+				devices[i].ModelInfo = modelInfo.Values
+				devices[i].Model = modelInfo.Model
+				devices[i].Capabilities = &caps
+			}
+			if devices[i].Capabilities == nil && devices[i].Dialect == api.DialectSkyfi {
+				caps := api.DialectSkyfi.Capabilities(api.DefaultCapabilities())
+				devices[i].Capabilities = &caps
+			}
+			sensorInfo, serr := client.FetchSensorInfo(ctx, devices[i].IP)
+			if serr == nil {
+				devices[i].SensorInfo = sensorInfo.Values
 			}
 		}()
 	}
 	wg.Wait()
-
-	// Save AC manufacturer MACs to config via central config package
-	var acMACs []string
-	for _, d := range filteredDevices {
-		if strings.Contains(strings.ToLower(d.Name), "murata") {
-			acMACs = append(acMACs, d.MAC)
-		}
-	}
-	if err := config.SaveACManufacturerMACs(acMACs); err != nil {
-		log.Printf("Warning: Failed to save AC manufacturer MACs to config: %v", err)
-	}
-
-	return filteredDevices, nil
-}
-
-// fuzzyFilter filters devices based on fuzzy pattern matching
-func fuzzyFilter(devices []Device, pattern string) []Device {
-	var filtered []Device
-
-	// Convert pattern to lowercase for case-insensitive matching
-	pattern = strings.ToLower(pattern)
-
-	for _, device := range devices {
-		// Check if device name contains the pattern (case-insensitive)
-		if strings.Contains(strings.ToLower(device.Name), pattern) {
-			filtered = append(filtered, device)
-		}
-	}
-
-	return filtered
 }
@@ -0,0 +1,119 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/romaingallez/clim_cli/internals/api"
+)
+
+// maxSweepHosts caps the number of addresses a sweep probes, to catch CIDR typos such as /8
+const maxSweepHosts = 1 << 16
+
+// SweepTimeout is the default time given to each host to answer basic_info during a sweep
+const SweepTimeout = 2 * time.Second
+
+// SweepDevices probes common/basic_info on every host of the CIDRs with at most workers
+// concurrent requests, and keeps the hosts answering with a ret=OK body.
+// The probe client should have retries disabled and a short timeout: most hosts never answer.
+func SweepDevices(probe *api.Client, cidrs []string, workers int) ([]Device, error) {
+	hosts, err := expandCIDRs(cidrs)
+	if err != nil {
+		return nil, err
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	log.Printf("Sweeping %d addresses in %v with %d workers", len(hosts), cidrs, workers)
+
+	var mu sync.Mutex
+	var devices []Device
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for _, ip := range hosts {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			dialect, info, err := probe.DetectDialect(context.Background(), ip)
+			if err != nil || info.Values["ret"] != "OK" {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			devices = append(devices, Device{
+				IP:        ip,
+				MAC:       info.HardwareAddr(),
+				Name:      info.Name,
+				Status:    "online",
+				Dialect:   dialect,
+				BasicInfo: info.Values,
+			})
+		}()
+	}
+	wg.Wait()
+
+	log.Printf("Found %d devices", len(devices))
+	return devices, nil
+}
+
+// expandCIDRs returns the host addresses of the IPv4 prefixes, without the network and
+// broadcast addresses when the prefix has them. A bare address counts as a /32, and an
+// "ip:port" entry is kept as is to reach adapters on other ports (e.g. the simulator).
+func expandCIDRs(cidrs []string) ([]string, error) {
+	if len(cidrs) == 0 {
+		return nil, fmt.Errorf("sweep needs at least one CIDR (--cidr)")
+	}
+
+	seen := make(map[string]bool)
+	var hosts []string
+	for _, cidr := range cidrs {
+		if addrPort, err := netip.ParseAddrPort(cidr); err == nil {
+			if host := addrPort.String(); !seen[host] {
+				seen[host] = true
+				hosts = append(hosts, host)
+			}
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			addr, aerr := netip.ParseAddr(cidr)
+			if aerr != nil {
+				return nil, fmt.Errorf("invalid CIDR %q: %v", cidr, err)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		if !prefix.Addr().Is4() {
+			return nil, fmt.Errorf("invalid CIDR %q: only IPv4 is supported", cidr)
+		}
+		prefix = prefix.Masked()
+		bits := 32 - prefix.Bits()
+		if bits > 16 {
+			return nil, fmt.Errorf("CIDR %q is too large (at most %d addresses per sweep)", cidr, maxSweepHosts)
+		}
+
+		var addrs []netip.Addr
+		for addr := prefix.Addr(); prefix.Contains(addr); addr = addr.Next() {
+			addrs = append(addrs, addr)
+		}
+		if bits >= 2 {
+			addrs = addrs[1 : len(addrs)-1]
+		}
+		for _, addr := range addrs {
+			if host := addr.String(); !seen[host] {
+				seen[host] = true
+				hosts = append(hosts, host)
+			}
+		}
+		if len(hosts) > maxSweepHosts {
+			return nil, fmt.Errorf("too many addresses to sweep (at most %d)", maxSweepHosts)
+		}
+	}
+	return hosts, nil
+}
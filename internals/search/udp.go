@@ -0,0 +1,116 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"time"
+
+	"github.com/romaingallez/clim_cli/internals/api"
+)
+
+const (
+	// udpDiscoveryPort is the port the adapters listen on for discovery broadcasts
+	udpDiscoveryPort = 30050
+	// udpReplyPort is the source port the adapters expect; they answer to it
+	udpReplyPort = 30000
+	// udpDiscoveryMessage asks every adapter to answer with its basic_info
+	udpDiscoveryMessage = "DAIKIN_UDP/common/basic_info"
+)
+
+// DiscoverUDP broadcasts the adapter discovery message on the interface (or on all
+// interfaces when ifaceString is empty) and collects the basic_info replies until timeout.
+// It needs no privileges.
+func DiscoverUDP(ifaceString string, timeout int) ([]Device, error) {
+	targets := []*net.UDPAddr{{IP: net.IPv4bcast, Port: udpDiscoveryPort}}
+	if ifaceString != "" {
+		iface, err := net.InterfaceByName(ifaceString)
+		if err != nil {
+			return nil, fmt.Errorf("interface %s not found: %v", ifaceString, err)
+		}
+		if bcast, err := getBroadcastAddress(iface); err == nil {
+			targets = append(targets, &net.UDPAddr{IP: bcast, Port: udpDiscoveryPort})
+		}
+	}
+
+	// Prefer the port the adapters answer to; fall back to any port when it is taken
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{Port: udpReplyPort})
+	if err != nil {
+		log.Printf("UDP port %d unavailable (%v), using a random port", udpReplyPort, err)
+		conn, err = net.ListenUDP("udp4", &net.UDPAddr{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to open UDP socket: %v", err)
+		}
+	}
+	defer conn.Close()
+
+	for _, addr := range targets {
+		log.Printf("Sending discovery broadcast to %s", addr)
+		if _, err := conn.WriteToUDP([]byte(udpDiscoveryMessage), addr); err != nil {
+			log.Printf("Warning: Failed to send discovery broadcast to %s: %v", addr, err)
+		}
+	}
+
+	if err := conn.SetReadDeadline(time.Now().Add(time.Duration(timeout) * time.Second)); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var devices []Device
+	buf := make([]byte, 2048)
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				break
+			}
+			return devices, fmt.Errorf("failed to read discovery replies: %v", err)
+		}
+		ip := from.IP.String()
+		if seen[ip] {
+			continue
+		}
+		info, err := api.ParseBasicInfo(string(buf[:n]))
+		if err != nil {
+			log.Printf("Ignoring discovery reply from %s: %v", ip, err)
+			continue
+		}
+		seen[ip] = true
+		devices = append(devices, Device{
+			IP:        ip,
+			MAC:       info.HardwareAddr(),
+			Name:      info.Name,
+			Status:    "online",
+			BasicInfo: info.Values,
+		})
+	}
+
+	log.Printf("Found %d devices", len(devices))
+	return devices, nil
+}
+
+// getBroadcastAddress returns the IPv4 directed broadcast address of the interface
+func getBroadcastAddress(iface *net.Interface) (net.IP, error) {
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		ip4 := ipNet.IP.To4()
+		if ip4 == nil || ip4[0] == 127 || len(ipNet.Mask) != net.IPv4len {
+			continue
+		}
+		bcast := make(net.IP, net.IPv4len)
+		for i := range ip4 {
+			bcast[i] = ip4[i] | ^ipNet.Mask[i]
+		}
+		return bcast, nil
+	}
+	return nil, errors.New("no valid IPv4 address found on interface")
+}
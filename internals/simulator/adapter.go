@@ -0,0 +1,222 @@
+package simulator
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Options controls the behaviour shared by the simulated adapters
+type Options struct {
+	Latency  time.Duration // Delay added before every response
+	FailRate float64       // Probability in [0, 1] that a request fails (503 on reads, ret=SERIAL NG on writes)
+}
+
+// Adapter is a fake wifi adapter serving the subset of the API used by clim_cli.
+// It keeps its control state in memory and lets the room temperature drift toward the setpoint.
+type Adapter struct {
+	Name  string
+	Group string
+	MAC   string // 12 hex digits, as reported in basic_info
+
+	opts Options
+
+	mu       sync.Mutex
+	pow      string
+	mode     string
+	stemp    string
+	shum     string
+	fRate    string
+	fDir     string
+	htemp    float64
+	otemp    float64
+	lastRead time.Time
+}
+
+// NewAdapter returns a powered off adapter in cool mode at 24°C with a 22°C room
+func NewAdapter(name, group, mac string, opts Options) *Adapter {
+	return &Adapter{
+		Name:     name,
+		Group:    group,
+		MAC:      mac,
+		opts:     opts,
+		pow:      "0",
+		mode:     "4",
+		stemp:    "24.0",
+		shum:     "0",
+		fRate:    "A",
+		fDir:     "0",
+		htemp:    22.0,
+		otemp:    14.0,
+		lastRead: time.Now(),
+	}
+}
+
+// ServeHTTP implements http.Handler. Unknown endpoints answer 404 like the real adapters.
+func (a *Adapter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if a.opts.Latency > 0 {
+		time.Sleep(a.opts.Latency)
+	}
+
+	write := r.URL.Path == "/aircon/set_control_info"
+	if a.opts.FailRate > 0 && rand.Float64() < a.opts.FailRate {
+		if write {
+			fmt.Fprint(w, "ret=SERIAL NG")
+			return
+		}
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var body string
+	switch r.URL.Path {
+	case "/common/basic_info":
+		body = a.basicInfo()
+	case "/aircon/get_control_info":
+		body = a.controlInfo()
+	case "/aircon/set_control_info":
+		body = a.setControlInfo(r)
+	case "/aircon/get_sensor_info":
+		body = a.sensorInfo()
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	fmt.Fprint(w, body)
+}
+
+func (a *Adapter) basicInfo() string {
+	return strings.Join([]string{
+		"ret=OK", "type=aircon", "reg=eu", "dst=1", "ver=1_2_51", "rev=D3A0C9F",
+		"pow=" + a.pow, "err=0", "location=0", "name=" + encode(a.Name), "icon=0",
+		"method=home only", "port=30050", "id=", "pw=", "lpw_flag=0", "adp_kind=3",
+		"pv=2", "cpv=2", "cpv_minor=00", "led=1", "en_setzone=1", "mac=" + a.MAC,
+		"adp_mode=run", "en_hol=0", "grp_name=" + encode(a.Group), "en_grp=1",
+	}, ",")
+}
+
+func (a *Adapter) controlInfo() string {
+	return strings.Join([]string{
+		"ret=OK", "pow=" + a.pow, "mode=" + a.mode, "adv=", "stemp=" + a.stemp,
+		"shum=" + a.shum, "f_rate=" + a.fRate, "f_dir=" + a.fDir,
+	}, ",")
+}
+
+// setControlInfo validates and applies a set_control_info request.
+// Like the adapters, every parameter is required and a bad value rejects the whole request.
+func (a *Adapter) setControlInfo(r *http.Request) string {
+	q := r.URL.Query()
+	for _, key := range []string{"pow", "mode", "stemp", "shum", "f_rate", "f_dir"} {
+		if !q.Has(key) {
+			return "ret=PARAM NG"
+		}
+	}
+	pow, mode, stemp := q.Get("pow"), q.Get("mode"), q.Get("stemp")
+	fRate, fDir := q.Get("f_rate"), q.Get("f_dir")
+
+	if pow != "0" && pow != "1" {
+		return "ret=PARAM NG"
+	}
+	if !slices.Contains([]string{"0", "1", "2", "3", "4", "6", "7"}, mode) {
+		return "ret=PARAM NG"
+	}
+	if !slices.Contains([]string{"A", "B", "3", "4", "5", "6", "7"}, fRate) || !slices.Contains([]string{"0", "1", "2", "3"}, fDir) {
+		return "ret=PARAM NG"
+	}
+	switch mode {
+	case "2", "3": // DRY and FAN have no setpoint
+		if !slices.Contains([]string{"M", "--"}, stemp) {
+			if _, err := parseSetpoint(stemp); err != nil {
+				return "ret=PARAM NG"
+			}
+		}
+	default:
+		t, err := parseSetpoint(stemp)
+		if err != nil {
+			return "ret=PARAM NG"
+		}
+		stemp = strconv.FormatFloat(t, 'f', 1, 64)
+	}
+
+	a.updateRoom()
+	a.pow, a.mode, a.stemp, a.shum = pow, mode, stemp, q.Get("shum")
+	a.fRate, a.fDir = fRate, fDir
+	return "ret=OK,adv="
+}
+
+func (a *Adapter) sensorInfo() string {
+	a.updateRoom()
+	cmpfreq := 0
+	if target, ok := a.target(); ok && abs(target-a.htemp) > 0.2 {
+		cmpfreq = 30 + int(abs(target-a.htemp)*10)
+	}
+	return fmt.Sprintf("ret=OK,htemp=%.1f,hhum=-,otemp=%.1f,err=0,cmpfreq=%d", a.htemp, a.otemp, cmpfreq)
+}
+
+// updateRoom moves the room temperature toward the setpoint when running,
+// toward the outdoor temperature otherwise, by 0.1°C per elapsed 10 seconds
+func (a *Adapter) updateRoom() {
+	now := time.Now()
+	steps := int(now.Sub(a.lastRead) / (10 * time.Second))
+	if steps == 0 {
+		return
+	}
+	a.lastRead = a.lastRead.Add(time.Duration(steps) * 10 * time.Second)
+
+	goal, ok := a.target()
+	if !ok {
+		goal = a.otemp
+	}
+	for i := 0; i < steps && abs(goal-a.htemp) >= 0.1; i++ {
+		if goal > a.htemp {
+			a.htemp += 0.1
+		} else {
+			a.htemp -= 0.1
+		}
+	}
+}
+
+// target returns the setpoint the unit is working toward, if any
+func (a *Adapter) target() (float64, bool) {
+	if a.pow != "1" {
+		return 0, false
+	}
+	t, err := parseSetpoint(a.stemp)
+	return t, err == nil
+}
+
+// parseSetpoint parses a temperature setpoint in the 10-32°C range accepted by the units
+func parseSetpoint(s string) (float64, error) {
+	t, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	if t < 10 || t > 32 {
+		return 0, fmt.Errorf("setpoint %s out of range", s)
+	}
+	return t, nil
+}
+
+// encode percent-encodes every byte, the way the adapters report names
+func encode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		fmt.Fprintf(&b, "%%%02x", s[i])
+	}
+	return b.String()
+}
+
+func abs(f float64) float64 {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
@@ -0,0 +1,64 @@
+package simulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// Simulator runs a set of fake adapters, each on its own port
+type Simulator struct {
+	Adapters []*Adapter
+	Addrs    []string // "host:port" of each adapter, in the order of Adapters
+
+	servers []*http.Server
+	wg      sync.WaitGroup
+}
+
+// Start listens on host at consecutive ports from basePort (0 picks free ports) and serves
+// count adapters named "Sim 1", "Sim 2"... in group "Simulated".
+// MACs are locally administered addresses 0a:51:00:00:00:NN.
+func Start(host string, basePort, count int, opts Options) (*Simulator, error) {
+	if count < 1 {
+		return nil, errors.New("count must be at least 1")
+	}
+
+	s := &Simulator{}
+	for i := 0; i < count; i++ {
+		port := 0
+		if basePort > 0 {
+			port = basePort + i
+		}
+		ln, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+		if err != nil {
+			s.Close()
+			return nil, fmt.Errorf("failed to listen for adapter %d: %v", i+1, err)
+		}
+
+		adapter := NewAdapter(fmt.Sprintf("Sim %d", i+1), "Simulated", fmt.Sprintf("0A51%08X", i+1), opts)
+		srv := &http.Server{Handler: adapter}
+		s.Adapters = append(s.Adapters, adapter)
+		s.Addrs = append(s.Addrs, ln.Addr().String())
+		s.servers = append(s.servers, srv)
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			_ = srv.Serve(ln)
+		}()
+	}
+	return s, nil
+}
+
+// Close stops every adapter and waits for the servers to return
+func (s *Simulator) Close() error {
+	for _, srv := range s.servers {
+		_ = srv.Shutdown(context.Background())
+	}
+	s.wg.Wait()
+	return nil
+}
@@ -0,0 +1,102 @@
+package simulator_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/romaingallez/clim_cli/internals/api"
+	"github.com/romaingallez/clim_cli/internals/search"
+	"github.com/romaingallez/clim_cli/internals/simulator"
+)
+
+// startSimulator starts count adapters on free local ports and stops them with the test
+func startSimulator(t *testing.T, count int, opts simulator.Options) *simulator.Simulator {
+	t.Helper()
+	sim, err := simulator.Start("127.0.0.1", 0, count, opts)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { sim.Close() })
+	return sim
+}
+
+func TestSetVerifySearch(t *testing.T) {
+	sim := startSimulator(t, 2, simulator.Options{})
+	client := api.NewClient()
+	ctx := context.Background()
+
+	want := api.Clim{IP: sim.Addrs[0], Power: "1", Mode: "4", Temp: "22", Shum: "0", FanRate: "5", FanDir: "1"}
+	if err := client.SetClim(ctx, want); err != nil {
+		t.Fatalf("SetClim: %v", err)
+	}
+	vctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := client.VerifyClim(vctx, want, 10*time.Millisecond); err != nil {
+		t.Fatalf("VerifyClim: %v", err)
+	}
+	info, err := client.FetchControlInfo(ctx, sim.Addrs[1])
+	if err != nil {
+		t.Fatalf("FetchControlInfo: %v", err)
+	}
+	if info.Power {
+		t.Error("the other adapter was powered on")
+	}
+
+	devices, err := search.SweepDevices(api.NewClient(api.WithRetry(api.RetryPolicy{MaxAttempts: 1})), sim.Addrs, 2)
+	if err != nil {
+		t.Fatalf("SweepDevices: %v", err)
+	}
+	var found []string
+	for _, d := range devices {
+		found = append(found, d.MAC+" "+d.Name)
+	}
+	slices.Sort(found)
+	if want := []string{"0a:51:00:00:00:01 Sim 1", "0a:51:00:00:00:02 Sim 2"}; !slices.Equal(found, want) {
+		t.Errorf("sweep found %q, want %q", found, want)
+	}
+}
+
+func TestVerifyReportsMismatch(t *testing.T) {
+	sim := startSimulator(t, 1, simulator.Options{})
+	client := api.NewClient()
+
+	want := api.Clim{IP: sim.Addrs[0], Power: "1", Mode: "4", Temp: "25", Shum: "0", FanRate: "A", FanDir: "0"}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := client.VerifyClim(ctx, want, 10*time.Millisecond)
+	var verifyErr *api.VerifyError
+	if !errors.As(err, &verifyErr) {
+		t.Fatalf("VerifyClim error = %v, want a VerifyError", err)
+	}
+}
+
+func TestParamNG(t *testing.T) {
+	sim := startSimulator(t, 1, simulator.Options{})
+	client := api.NewClient()
+
+	err := client.SetClim(context.Background(), api.Clim{IP: sim.Addrs[0], Power: "1", Mode: "4", Temp: "hot", Shum: "0", FanRate: "A", FanDir: "0"})
+	if !errors.Is(err, api.ErrParamNG) {
+		t.Fatalf("SetClim error = %v, want ErrParamNG", err)
+	}
+}
+
+func TestSerialNG(t *testing.T) {
+	sim := startSimulator(t, 1, simulator.Options{FailRate: 1})
+	client := api.NewClient(api.WithRetry(api.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}))
+	ctx := context.Background()
+
+	err := client.SetClim(ctx, api.Clim{IP: sim.Addrs[0], Power: "1", Mode: "4", Temp: "24", Shum: "0", FanRate: "A", FanDir: "0"})
+	if !errors.Is(err, api.ErrSerialNG) {
+		t.Fatalf("SetClim error = %v, want ErrSerialNG", err)
+	}
+
+	_, err = client.FetchControlInfo(ctx, sim.Addrs[0])
+	var statusErr *api.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("FetchControlInfo error = %v, want http 503", err)
+	}
+}
@@ -0,0 +1,613 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Buckets of the bbolt store. Snapshots and changes live in one sub-bucket per MAC, keyed
+// by time so that reading the latest state never loads the archived snapshots; summaries
+// are keyed by date.
+var (
+	bucketDevices   = []byte("devices")      // mac -> boltDevice
+	bucketSnapshots = []byte("snapshots")    // mac -> (time+seq -> DeviceSnapshot)
+	bucketChanges   = []byte("changes")      // mac -> (time+seq -> DeviceChange)
+	bucketSummaries = []byte("summaries")    // mac -> (date -> DailySummary)
+	bucketIP        = []byte("index_ip")     // ip -> mac
+	bucketGroup     = []byte("index_group")  // group + 0x00 + mac -> nil
+	bucketChangedAt = []byte("index_change") // time + mac -> nil
+	bucketMeta      = []byte("meta")         // keyMetaSchema -> schema version
+)
+
+// dataBuckets hold the histories and their indexes; they are rebuilt by migrations
+var dataBuckets = [][]byte{bucketDevices, bucketSnapshots, bucketChanges, bucketSummaries, bucketIP, bucketGroup, bucketChangedAt}
+
+var keyMetaSchema = []byte("schema_version")
+
+// boltDevice is the latest state of a device in the devices bucket
+type boltDevice struct {
+	MAC       string           `json:"mac"`
+	Device    DeviceSnapshot   `json:"device"`
+	Transport *DeviceTransport `json:"transport,omitempty"`
+}
+
+// boltStore keeps the histories in an embedded bbolt database
+type boltStore struct {
+	db *bolt.DB
+}
+
+// OpenBoltStore opens (or creates) the bbolt database at path.
+// It waits up to 5 seconds for another clim_cli process holding the database.
+func OpenBoltStore(path string) (Store, error) {
+	db, err := bolt.Open(path, StoreFileMode, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %v", path, err)
+	}
+	if initialized(db) {
+		return &boltStore{db: db}, nil
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range append(dataBuckets, bucketMeta) {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		// New databases start at the current schema; databases without a version predate it
+		if tx.Bucket(bucketMeta).Get(keyMetaSchema) == nil && tx.Bucket(bucketDevices).Stats().KeyN == 0 {
+			return putSchemaVersion(tx, SchemaVersion)
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize database %s: %v", path, err)
+	}
+
+	return &boltStore{db: db}, nil
+}
+
+// initialized reports whether every bucket exists and the schema version is set, in which
+// case opening the database needs no write transaction
+func initialized(db *bolt.DB) bool {
+	ok := false
+	_ = db.View(func(tx *bolt.Tx) error {
+		for _, name := range append(dataBuckets, bucketMeta) {
+			if tx.Bucket(name) == nil {
+				return nil
+			}
+		}
+		ok = tx.Bucket(bucketMeta).Get(keyMetaSchema) != nil
+		return nil
+	})
+	return ok
+}
+
+func (s *boltStore) Get(mac string) (*DeviceHistory, error) {
+	var history *DeviceHistory
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		history, err = readHistory(tx, mac)
+		return err
+	})
+	return history, err
+}
+
+func (s *boltStore) List() ([]*DeviceHistory, error) {
+	var histories []*DeviceHistory
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketDevices).ForEach(func(k, _ []byte) error {
+			history, err := readHistory(tx, string(k))
+			if err != nil {
+				return err
+			}
+			histories = append(histories, history)
+			return nil
+		})
+	})
+	return histories, err
+}
+
+func (s *boltStore) FindByIP(ip string) (*DeviceHistory, error) {
+	var history *DeviceHistory
+	err := s.db.View(func(tx *bolt.Tx) error {
+		mac := tx.Bucket(bucketIP).Get([]byte(ip))
+		if mac == nil {
+			return ErrNotFound
+		}
+		var err error
+		history, err = readHistory(tx, string(mac))
+		return err
+	})
+	return history, err
+}
+
+func (s *boltStore) ListByGroup(group string) ([]*DeviceHistory, error) {
+	var histories []*DeviceHistory
+	prefix := append([]byte(group), 0)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketGroup).Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			history, err := readHistory(tx, string(k[len(prefix):]))
+			if err != nil {
+				return err
+			}
+			histories = append(histories, history)
+		}
+		return nil
+	})
+	return histories, err
+}
+
+func (s *boltStore) ChangedSince(t time.Time) ([]*DeviceHistory, error) {
+	var histories []*DeviceHistory
+	err := s.db.View(func(tx *bolt.Tx) error {
+		seen := make(map[string]bool)
+		c := tx.Bucket(bucketChangedAt).Cursor()
+		// Seek past every key of t itself: changes must be strictly after t
+		for k, _ := c.Seek(timeKey(t.Add(time.Nanosecond))); k != nil; k, _ = c.Next() {
+			mac := string(k[8:])
+			if seen[mac] {
+				continue
+			}
+			seen[mac] = true
+			history, err := readHistory(tx, mac)
+			if err != nil {
+				return err
+			}
+			histories = append(histories, history)
+		}
+		return nil
+	})
+	return histories, err
+}
+
+func (s *boltStore) Snapshots(mac string) ([]DeviceSnapshot, error) {
+	var snapshots []DeviceSnapshot
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(bucketDevices).Get([]byte(mac)) == nil {
+			return ErrNotFound
+		}
+		b := tx.Bucket(bucketSnapshots).Bucket([]byte(mac))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(_, v []byte) error {
+			var snapshot DeviceSnapshot
+			if err := json.Unmarshal(v, &snapshot); err != nil {
+				return fmt.Errorf("failed to decode snapshot of %s: %v", mac, err)
+			}
+			snapshots = append(snapshots, snapshot)
+			return nil
+		})
+	})
+	return snapshots, err
+}
+
+func (s *boltStore) Save(updates ...Update) error {
+	return s.Write(func(tx Tx) error {
+		return tx.Save(updates...)
+	})
+}
+
+// Write runs fn in a single bbolt write transaction
+func (s *boltStore) Write(fn func(Tx) error) error {
+	btx := &boltTx{}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		btx.tx = tx
+		return fn(btx)
+	})
+	if err != nil {
+		return err
+	}
+	if btx.dirty {
+		log.Printf("Saved device storage to %s", s.db.Path())
+	}
+	return nil
+}
+
+func (s *boltStore) Migrate(dryRun bool) (*MigrationReport, error) {
+	// Databases are nearly always current: check without taking the write lock first
+	var report *MigrationReport
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if schemaVersion(tx) == SchemaVersion {
+			report = &MigrationReport{From: SchemaVersion, To: SchemaVersion, Devices: tx.Bucket(bucketDevices).Stats().KeyN}
+		}
+		return nil
+	})
+	if err != nil || report != nil {
+		return report, err
+	}
+
+	run := s.db.Update
+	if dryRun {
+		run = s.db.View
+	}
+	err = run(func(tx *bolt.Tx) error {
+		if schemaVersion(tx) == SchemaVersion {
+			report = &MigrationReport{From: SchemaVersion, To: SchemaVersion, Devices: tx.Bucket(bucketDevices).Stats().KeyN}
+			return nil
+		}
+
+		doc, err := exportDocument(tx)
+		if err != nil {
+			return err
+		}
+		if report, err = migrateDocument(doc); err != nil || dryRun {
+			return err
+		}
+
+		// Rewrite every bucket from the migrated document
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		var storage DeviceStorage
+		if err := json.Unmarshal(data, &storage); err != nil {
+			return fmt.Errorf("failed to decode migrated data: %v", err)
+		}
+		for _, name := range dataBuckets {
+			if err := tx.DeleteBucket(name); err != nil {
+				return err
+			}
+			if _, err := tx.CreateBucket(name); err != nil {
+				return err
+			}
+		}
+		for mac, history := range storage.Devices {
+			if history.MAC == "" {
+				history.MAC = mac
+			}
+			if err := writeUpdate(tx, Update{History: history, Snapshots: history.Snapshots, Changes: history.Changes, Summaries: history.Summaries}); err != nil {
+				return err
+			}
+		}
+		return putSchemaVersion(tx, SchemaVersion)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate database: %v", err)
+	}
+	return report, nil
+}
+
+func (s *boltStore) Prune(policy RetentionPolicy, now time.Time, dryRun bool) (*PruneReport, error) {
+	var report *PruneReport
+	run := s.db.Update
+	if dryRun {
+		run = s.db.View
+	}
+	err := run(func(tx *bolt.Tx) error {
+		var err error
+		report, err = pruneTx(tx, policy, now, dryRun)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to prune database: %v", err)
+	}
+	return report, nil
+}
+
+func (s *boltStore) Close() error {
+	return s.db.Close()
+}
+
+// boltTx is the transaction of boltStore.Write
+type boltTx struct {
+	tx    *bolt.Tx
+	dirty bool // Whether anything was written
+}
+
+func (t *boltTx) Get(mac string) (*DeviceHistory, error) {
+	return readHistory(t.tx, mac)
+}
+
+func (t *boltTx) Save(updates ...Update) error {
+	for _, u := range updates {
+		if err := writeUpdate(t.tx, u); err != nil {
+			return fmt.Errorf("failed to save to database: %v", err)
+		}
+	}
+	t.dirty = true
+	return nil
+}
+
+func (t *boltTx) Prune(policy RetentionPolicy, now time.Time) (*PruneReport, error) {
+	report, err := pruneTx(t.tx, policy, now, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prune database: %v", err)
+	}
+	return report, nil
+}
+
+// pruneTx applies the retention policy in tx; with dryRun it only reports what would be dropped
+func pruneTx(tx *bolt.Tx, policy RetentionPolicy, now time.Time, dryRun bool) (*PruneReport, error) {
+	report := &PruneReport{}
+	err := tx.Bucket(bucketDevices).ForEach(func(mac, _ []byte) error {
+		b := tx.Bucket(bucketSnapshots).Bucket(mac)
+		if b == nil {
+			return nil
+		}
+		var keys [][]byte
+		var snapshots []DeviceSnapshot
+		err := b.ForEach(func(k, v []byte) error {
+			var snapshot DeviceSnapshot
+			if err := json.Unmarshal(v, &snapshot); err != nil {
+				return fmt.Errorf("failed to decode snapshot of %s: %v", mac, err)
+			}
+			keys = append(keys, append([]byte(nil), k...))
+			snapshots = append(snapshots, snapshot)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		var dropped []DeviceSnapshot
+		for i, drop := range policy.dropped(snapshots, now) {
+			if !drop {
+				report.Kept++
+				continue
+			}
+			dropped = append(dropped, snapshots[i])
+			if !dryRun {
+				if err := b.Delete(keys[i]); err != nil {
+					return err
+				}
+			}
+		}
+		if len(dropped) == 0 {
+			return nil
+		}
+		existing, err := readSummaries(tx, mac)
+		if err != nil {
+			return err
+		}
+		summaries := summarize(existing, dropped)
+		report.Devices++
+		report.Dropped += len(dropped)
+		report.Summaries += len(summaries)
+		if dryRun {
+			return nil
+		}
+		return putSummaries(tx, mac, summaries)
+	})
+	return report, err
+}
+
+// schemaVersion returns the schema version of the database, 0 when it predates versioning
+func schemaVersion(tx *bolt.Tx) int {
+	v := tx.Bucket(bucketMeta).Get(keyMetaSchema)
+	if len(v) != 8 {
+		return 0
+	}
+	return int(binary.BigEndian.Uint64(v))
+}
+
+func putSchemaVersion(tx *bolt.Tx, version int) error {
+	return tx.Bucket(bucketMeta).Put(keyMetaSchema, binary.BigEndian.AppendUint64(nil, uint64(version)))
+}
+
+// exportDocument returns the database in the generic JSON form of DeviceStorage used by the
+// migrations. Records are kept as raw JSON so that fields unknown to the current structs survive.
+func exportDocument(tx *bolt.Tx) (map[string]any, error) {
+	devices := make(map[string]any)
+	err := tx.Bucket(bucketDevices).ForEach(func(k, v []byte) error {
+		var h map[string]any
+		if err := json.Unmarshal(v, &h); err != nil {
+			return fmt.Errorf("failed to decode device %s: %v", k, err)
+		}
+		for key, parent := range map[string][]byte{"snapshots": bucketSnapshots, "changes": bucketChanges, "summaries": bucketSummaries} {
+			records := []any{}
+			if b := tx.Bucket(parent).Bucket(k); b != nil {
+				err := b.ForEach(func(_, rv []byte) error {
+					var r any
+					if err := json.Unmarshal(rv, &r); err != nil {
+						return err
+					}
+					records = append(records, r)
+					return nil
+				})
+				if err != nil {
+					return fmt.Errorf("failed to decode %s of %s: %v", key, k, err)
+				}
+			}
+			h[key] = records
+		}
+		devices[string(k)] = h
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"schema_version": float64(schemaVersion(tx)), "devices": devices}, nil
+}
+
+// readHistory returns the latest state, the changes and the summaries of a device
+func readHistory(tx *bolt.Tx, mac string) (*DeviceHistory, error) {
+	data := tx.Bucket(bucketDevices).Get([]byte(mac))
+	if data == nil {
+		return nil, ErrNotFound
+	}
+	var dev boltDevice
+	if err := json.Unmarshal(data, &dev); err != nil {
+		return nil, fmt.Errorf("failed to decode device %s: %v", mac, err)
+	}
+
+	history := &DeviceHistory{MAC: dev.MAC, Device: dev.Device, Transport: dev.Transport, Changes: []DeviceChange{}}
+	if b := tx.Bucket(bucketChanges).Bucket([]byte(mac)); b != nil {
+		err := b.ForEach(func(_, v []byte) error {
+			var change DeviceChange
+			if err := json.Unmarshal(v, &change); err != nil {
+				return fmt.Errorf("failed to decode change of %s: %v", mac, err)
+			}
+			history.Changes = append(history.Changes, change)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	summaries, err := readSummaries(tx, []byte(mac))
+	if err != nil {
+		return nil, err
+	}
+	history.Summaries = summaries
+	return history, nil
+}
+
+// readSummaries returns the daily summaries of a device, sorted by date
+func readSummaries(tx *bolt.Tx, mac []byte) ([]DailySummary, error) {
+	summaries := []DailySummary{}
+	b := tx.Bucket(bucketSummaries).Bucket(mac)
+	if b == nil {
+		return summaries, nil
+	}
+	err := b.ForEach(func(_, v []byte) error {
+		var summary DailySummary
+		if err := json.Unmarshal(v, &summary); err != nil {
+			return fmt.Errorf("failed to decode summary of %s: %v", mac, err)
+		}
+		summaries = append(summaries, summary)
+		return nil
+	})
+	return summaries, err
+}
+
+// putSummaries stores the summaries of a device, replacing those of the same dates
+func putSummaries(tx *bolt.Tx, mac []byte, summaries []DailySummary) error {
+	if len(summaries) == 0 {
+		return nil
+	}
+	b, err := tx.Bucket(bucketSummaries).CreateBucketIfNotExists(mac)
+	if err != nil {
+		return err
+	}
+	for _, summary := range summaries {
+		data, err := json.Marshal(summary)
+		if err != nil {
+			return err
+		}
+		if err := b.Put([]byte(summary.Date), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeUpdate stores the latest state of the device, appends its snapshots and changes,
+// stores its summaries and keeps the indexes in sync
+func writeUpdate(tx *bolt.Tx, u Update) error {
+	mac := []byte(u.History.MAC)
+	devices := tx.Bucket(bucketDevices)
+
+	// Drop the index entries of the previous state
+	if data := devices.Get(mac); data != nil {
+		var old boltDevice
+		if err := json.Unmarshal(data, &old); err == nil {
+			if ip := []byte(old.Device.IP); bytes.Equal(tx.Bucket(bucketIP).Get(ip), mac) {
+				if err := tx.Bucket(bucketIP).Delete(ip); err != nil {
+					return err
+				}
+			}
+			if err := tx.Bucket(bucketGroup).Delete(groupKey(old.Device.BasicInfo["grp_name"], mac)); err != nil {
+				return err
+			}
+		}
+	}
+	if u.Replace {
+		if err := dropHistory(tx, mac); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.Marshal(boltDevice{MAC: u.History.MAC, Device: u.History.Device, Transport: u.History.Transport})
+	if err != nil {
+		return err
+	}
+	if err := devices.Put(mac, data); err != nil {
+		return err
+	}
+	if ip := u.History.Device.IP; ip != "" {
+		if err := tx.Bucket(bucketIP).Put([]byte(ip), mac); err != nil {
+			return err
+		}
+	}
+	if err := tx.Bucket(bucketGroup).Put(groupKey(groupOf(u.History), mac), nil); err != nil {
+		return err
+	}
+
+	for _, snapshot := range u.Snapshots {
+		if err := appendRecord(tx, bucketSnapshots, mac, snapshot.LastSeenAt, snapshot); err != nil {
+			return err
+		}
+	}
+	for _, change := range u.Changes {
+		if err := appendRecord(tx, bucketChanges, mac, change.ChangedAt, change); err != nil {
+			return err
+		}
+		if err := tx.Bucket(bucketChangedAt).Put(append(timeKey(change.ChangedAt), mac...), nil); err != nil {
+			return err
+		}
+	}
+	return putSummaries(tx, mac, u.Summaries)
+}
+
+// dropHistory deletes the snapshots, changes and summaries of a device
+func dropHistory(tx *bolt.Tx, mac []byte) error {
+	for _, name := range [][]byte{bucketSnapshots, bucketChanges, bucketSummaries} {
+		if tx.Bucket(name).Bucket(mac) != nil {
+			if err := tx.Bucket(name).DeleteBucket(mac); err != nil {
+				return err
+			}
+		}
+	}
+	var stale [][]byte
+	c := tx.Bucket(bucketChangedAt).Cursor()
+	for k, _ := c.First(); k != nil; k, _ = c.Next() {
+		if bytes.Equal(k[8:], mac) {
+			stale = append(stale, append([]byte(nil), k...))
+		}
+	}
+	for _, k := range stale {
+		if err := tx.Bucket(bucketChangedAt).Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendRecord stores v in the per-device sub-bucket of parent, keyed by time then sequence
+func appendRecord(tx *bolt.Tx, parent, mac []byte, t time.Time, v any) error {
+	b, err := tx.Bucket(parent).CreateBucketIfNotExists(mac)
+	if err != nil {
+		return err
+	}
+	seq, err := b.NextSequence()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	key := binary.BigEndian.AppendUint64(timeKey(t), seq)
+	return b.Put(key, data)
+}
+
+// timeKey encodes t so that keys sort chronologically; times before 1970 sort first
+func timeKey(t time.Time) []byte {
+	n := t.UnixNano()
+	if t.Before(time.Unix(0, 0)) {
+		n = 0
+	}
+	return binary.BigEndian.AppendUint64(nil, uint64(n))
+}
+
+func groupKey(group string, mac []byte) []byte {
+	return append(append([]byte(group), 0), mac...)
+}
@@ -0,0 +1,322 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/romaingallez/clim_cli/internals/safefile"
+)
+
+// jsonStore keeps every history in a single JSON file, loaded and rewritten as a whole.
+// Reads hold a shared lock and saves an exclusive one across processes; the file is
+// replaced atomically and a corrupt file falls back to the devices.json.bak copy.
+type jsonStore struct {
+	path string
+}
+
+// NewJSONStore returns a Store backed by the devices.json file at path
+func NewJSONStore(path string) Store {
+	return &jsonStore{path: path}
+}
+
+// read loads the file under a shared lock
+func (s *jsonStore) read() (*DeviceStorage, error) {
+	lock, err := safefile.RLockFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+	return s.load()
+}
+
+// load reads the file; the caller holds a lock
+func (s *jsonStore) load() (*DeviceStorage, error) {
+	data, fromBackup, err := s.readRaw()
+	if errors.Is(err, os.ErrNotExist) {
+		// Return empty storage if file doesn't exist
+		return &DeviceStorage{
+			SchemaVersion: SchemaVersion,
+			Devices:       make(map[string]*DeviceHistory),
+			LastUpdated:   time.Now(),
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read storage file: %v", err)
+	}
+	if fromBackup {
+		log.Printf("Warning: %s is corrupt, using %s%s", s.path, s.path, safefile.BackupSuffix)
+	}
+
+	// Older files are migrated in memory; the next save writes the current schema
+	storage, _, err := decodeDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode storage data: %v", err)
+	}
+
+	// Initialize devices map if nil
+	if storage.Devices == nil {
+		storage.Devices = make(map[string]*DeviceHistory)
+	}
+
+	return storage, nil
+}
+
+// readRaw reads the file, falling back to the backup when it is not valid JSON
+func (s *jsonStore) readRaw() ([]byte, bool, error) {
+	return safefile.ReadFile(s.path, func(data []byte) error {
+		if !json.Valid(data) {
+			return errors.New("invalid JSON")
+		}
+		return nil
+	})
+}
+
+func (s *jsonStore) save(storage *DeviceStorage) error {
+	storage.SchemaVersion = SchemaVersion
+	storage.LastUpdated = time.Now()
+
+	data, err := json.MarshalIndent(storage, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal storage data: %v", err)
+	}
+
+	if err := safefile.WriteFile(s.path, data, StoreFileMode); err != nil {
+		return fmt.Errorf("failed to write storage file: %v", err)
+	}
+
+	log.Printf("Saved device storage to %s", s.path)
+	return nil
+}
+
+func (s *jsonStore) Get(mac string) (*DeviceHistory, error) {
+	storage, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	history, exists := storage.Devices[mac]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return withoutSnapshots(history), nil
+}
+
+func (s *jsonStore) List() ([]*DeviceHistory, error) {
+	return s.filter(func(*DeviceHistory) bool { return true })
+}
+
+func (s *jsonStore) FindByIP(ip string) (*DeviceHistory, error) {
+	histories, err := s.filter(func(h *DeviceHistory) bool { return h.Device.IP == ip })
+	if err != nil {
+		return nil, err
+	}
+	if len(histories) == 0 {
+		return nil, ErrNotFound
+	}
+	return histories[0], nil
+}
+
+func (s *jsonStore) ListByGroup(group string) ([]*DeviceHistory, error) {
+	return s.filter(func(h *DeviceHistory) bool { return groupOf(h) == group })
+}
+
+func (s *jsonStore) ChangedSince(t time.Time) ([]*DeviceHistory, error) {
+	return s.filter(func(h *DeviceHistory) bool {
+		for _, change := range h.Changes {
+			if change.ChangedAt.After(t) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func (s *jsonStore) Snapshots(mac string) ([]DeviceSnapshot, error) {
+	storage, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	history, exists := storage.Devices[mac]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return history.Snapshots, nil
+}
+
+func (s *jsonStore) Save(updates ...Update) error {
+	return s.Write(func(tx Tx) error {
+		return tx.Save(updates...)
+	})
+}
+
+// Write loads the file once under an exclusive lock, runs fn on it in memory and writes
+// it back once if fn changed anything
+func (s *jsonStore) Write(fn func(Tx) error) error {
+	lock, err := safefile.LockFile(s.path)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	storage, err := s.load()
+	if err != nil {
+		return fmt.Errorf("failed to load device storage: %v", err)
+	}
+	tx := &jsonTx{storage: storage}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if !tx.dirty {
+		return nil
+	}
+	return s.save(storage)
+}
+
+func (s *jsonStore) Prune(policy RetentionPolicy, now time.Time, dryRun bool) (*PruneReport, error) {
+	if dryRun {
+		storage, err := s.read()
+		if err != nil {
+			return nil, err
+		}
+		return pruneStorage(storage, policy, now), nil
+	}
+
+	var report *PruneReport
+	err := s.Write(func(tx Tx) error {
+		var err error
+		report, err = tx.Prune(policy, now)
+		return err
+	})
+	return report, err
+}
+
+// jsonTx is the in-memory transaction of jsonStore.Write
+type jsonTx struct {
+	storage *DeviceStorage
+	dirty   bool // Whether the storage must be written back
+}
+
+func (tx *jsonTx) Get(mac string) (*DeviceHistory, error) {
+	history, exists := tx.storage.Devices[mac]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return withoutSnapshots(history), nil
+}
+
+func (tx *jsonTx) Save(updates ...Update) error {
+	for _, u := range updates {
+		history, exists := tx.storage.Devices[u.History.MAC]
+		if !exists || u.Replace {
+			history = &DeviceHistory{MAC: u.History.MAC, Snapshots: []DeviceSnapshot{}, Changes: []DeviceChange{}, Summaries: []DailySummary{}}
+			tx.storage.Devices[u.History.MAC] = history
+		}
+		history.Device = u.History.Device
+		history.Transport = u.History.Transport
+		history.Snapshots = append(history.Snapshots, u.Snapshots...)
+		history.Changes = append(history.Changes, u.Changes...)
+		if len(u.Summaries) > 0 {
+			history.Summaries = mergeSummaries(history.Summaries, u.Summaries)
+		}
+	}
+	tx.dirty = true
+	return nil
+}
+
+func (tx *jsonTx) Prune(policy RetentionPolicy, now time.Time) (*PruneReport, error) {
+	report := pruneStorage(tx.storage, policy, now)
+	if report.Dropped > 0 {
+		tx.dirty = true
+	}
+	return report, nil
+}
+
+// pruneStorage applies the retention policy to the histories in memory
+func pruneStorage(storage *DeviceStorage, policy RetentionPolicy, now time.Time) *PruneReport {
+	report := &PruneReport{}
+	for _, history := range storage.Devices {
+		drop := policy.dropped(history.Snapshots, now)
+		var kept, dropped []DeviceSnapshot
+		for i, snapshot := range history.Snapshots {
+			if drop[i] {
+				dropped = append(dropped, snapshot)
+			} else {
+				kept = append(kept, snapshot)
+			}
+		}
+		report.Kept += len(kept)
+		if len(dropped) == 0 {
+			continue
+		}
+		summaries := summarize(history.Summaries, dropped)
+		report.Devices++
+		report.Dropped += len(dropped)
+		report.Summaries += len(summaries)
+
+		history.Snapshots = append([]DeviceSnapshot{}, kept...)
+		history.Summaries = mergeSummaries(history.Summaries, summaries)
+	}
+	return report
+}
+
+func (s *jsonStore) Migrate(dryRun bool) (*MigrationReport, error) {
+	lock, err := safefile.LockFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	data, _, err := s.readRaw()
+	if errors.Is(err, os.ErrNotExist) {
+		return &MigrationReport{From: SchemaVersion, To: SchemaVersion}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read storage file: %v", err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal storage data: %v", err)
+	}
+	report, err := migrateDocument(doc)
+	if err != nil || dryRun || len(report.Steps) == 0 {
+		return report, err
+	}
+
+	if data, err = json.Marshal(doc); err != nil {
+		return nil, err
+	}
+	var storage DeviceStorage
+	if err := json.Unmarshal(data, &storage); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal migrated storage data: %v", err)
+	}
+	return report, s.save(&storage)
+}
+
+func (s *jsonStore) Close() error {
+	return nil
+}
+
+// filter returns the histories for which keep is true
+func (s *jsonStore) filter(keep func(*DeviceHistory) bool) ([]*DeviceHistory, error) {
+	storage, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	var histories []*DeviceHistory
+	for _, history := range storage.Devices {
+		if keep(history) {
+			histories = append(histories, withoutSnapshots(history))
+		}
+	}
+	return histories, nil
+}
+
+// withoutSnapshots returns a shallow copy of the history without its archived snapshots
+func withoutSnapshots(h *DeviceHistory) *DeviceHistory {
+	c := *h
+	c.Snapshots = nil
+	return &c
+}
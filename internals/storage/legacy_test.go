@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeprecatedStorageHelpers(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	now := time.Now().UTC().Truncate(time.Second)
+	snapshot := DeviceSnapshot{MAC: testMAC, Name: "Office", DiscoveredAt: now, LastSeenAt: now}
+	err := SaveDeviceStorage(&DeviceStorage{Devices: map[string]*DeviceHistory{
+		testMAC: {MAC: testMAC, Device: snapshot, Snapshots: []DeviceSnapshot{snapshot}},
+	}})
+	if err != nil {
+		t.Fatalf("SaveDeviceStorage: %v", err)
+	}
+
+	storage, err := LoadDeviceStorage()
+	if err != nil {
+		t.Fatalf("LoadDeviceStorage: %v", err)
+	}
+	h := storage.Devices[testMAC]
+	if h == nil || h.Device.Name != "Office" || len(h.Snapshots) != 1 {
+		t.Fatalf("loaded storage = %+v", storage.Devices)
+	}
+}
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaVersion is the storage format written by this version of clim_cli.
+// Bump it together with a new entry in migrations when DeviceStorage, DeviceHistory or
+// DeviceSnapshot change in a way older files do not decode into.
+const SchemaVersion = 1
+
+// Migration upgrades a storage document to Version. Migrate works on the generic JSON form
+// of DeviceStorage ({"devices": {mac: history}}) so that it can read fields the current
+// structs no longer have.
+type Migration struct {
+	Version     int
+	Description string
+	Migrate     func(doc map[string]any) error
+}
+
+// migrations is the ordered registry: entry i upgrades schema i to i+1.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "add schema_version; fill missing history MACs and snapshot/change/summary lists",
+		Migrate: func(doc map[string]any) error {
+			return eachHistory(doc, func(mac string, h map[string]any) error {
+				if s, _ := h["mac"].(string); s == "" {
+					h["mac"] = mac
+				}
+				for _, key := range []string{"snapshots", "changes", "summaries"} {
+					if _, ok := h[key].([]any); !ok {
+						h[key] = []any{}
+					}
+				}
+				return nil
+			})
+		},
+	},
+}
+
+// MigrationStep reports one migration of a run
+type MigrationStep struct {
+	Version     int
+	Description string
+	Changed     int // Devices modified by the step
+}
+
+// MigrationReport reports the migrations needed (dry run) or applied to a store
+type MigrationReport struct {
+	From    int
+	To      int
+	Devices int
+	Steps   []MigrationStep
+}
+
+// migrateDocument runs the migrations the document needs and sets its schema_version
+func migrateDocument(doc map[string]any) (*MigrationReport, error) {
+	from := 0
+	if v, ok := doc["schema_version"].(float64); ok {
+		from = int(v)
+	}
+	if from > SchemaVersion {
+		return nil, fmt.Errorf("storage schema version %d is newer than this clim_cli supports (%d); upgrade clim_cli", from, SchemaVersion)
+	}
+
+	devices, _ := doc["devices"].(map[string]any)
+	report := &MigrationReport{From: from, To: SchemaVersion, Devices: len(devices)}
+	for _, m := range migrations[from:] {
+		before := deviceFingerprints(doc)
+		if err := m.Migrate(doc); err != nil {
+			return nil, fmt.Errorf("migration to schema version %d failed: %w", m.Version, err)
+		}
+		after := deviceFingerprints(doc)
+		changed := 0
+		for mac, fp := range after {
+			if before[mac] != fp {
+				changed++
+			}
+		}
+		report.Steps = append(report.Steps, MigrationStep{Version: m.Version, Description: m.Description, Changed: changed})
+	}
+	doc["schema_version"] = SchemaVersion
+	return report, nil
+}
+
+// decodeDocument unmarshals data, migrating it to SchemaVersion first when it is older.
+// Documents at the current schema are decoded once, straight into DeviceStorage.
+func decodeDocument(data []byte) (*DeviceStorage, *MigrationReport, error) {
+	var current DeviceStorage
+	if err := json.Unmarshal(data, &current); err == nil && current.SchemaVersion == SchemaVersion {
+		return &current, &MigrationReport{From: SchemaVersion, To: SchemaVersion, Devices: len(current.Devices)}, nil
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	report, err := migrateDocument(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(report.Steps) > 0 {
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, nil, err
+		}
+	}
+	var storage DeviceStorage
+	if err := json.Unmarshal(data, &storage); err != nil {
+		return nil, nil, err
+	}
+	return &storage, report, nil
+}
+
+// eachHistory calls fn with every device history of the document
+func eachHistory(doc map[string]any, fn func(mac string, h map[string]any) error) error {
+	devices, _ := doc["devices"].(map[string]any)
+	for mac, v := range devices {
+		h, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("device %s: history is not an object", mac)
+		}
+		if err := fn(mac, h); err != nil {
+			return fmt.Errorf("device %s: %w", mac, err)
+		}
+	}
+	return nil
+}
+
+// deviceFingerprints returns the JSON encoding of every device history, to detect changes
+func deviceFingerprints(doc map[string]any) map[string]string {
+	devices, _ := doc["devices"].(map[string]any)
+	fps := make(map[string]string, len(devices))
+	for mac, h := range devices {
+		data, _ := json.Marshal(h)
+		fps[mac] = string(data)
+	}
+	return fps
+}
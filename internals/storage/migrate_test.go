@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDecodeDocument(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantFrom  int
+		wantSteps int
+		wantErr   bool
+	}{
+		{
+			name:      "unversioned",
+			data:      `{"devices": {"0a:51:00:00:00:01": {"device": {"name": "Office"}}}}`,
+			wantFrom:  0,
+			wantSteps: SchemaVersion,
+		},
+		{
+			name:     "current",
+			data:     `{"schema_version": 1, "devices": {"0a:51:00:00:00:01": {"mac": "0a:51:00:00:00:01", "device": {"name": "Office"}, "snapshots": [], "changes": [], "summaries": []}}}`,
+			wantFrom: SchemaVersion,
+		},
+		{
+			name:    "newer",
+			data:    `{"schema_version": 99, "devices": {}}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage, report, err := decodeDocument([]byte(tt.data))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("decodeDocument succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeDocument: %v", err)
+			}
+			if report.From != tt.wantFrom || report.To != SchemaVersion || len(report.Steps) != tt.wantSteps {
+				t.Errorf("report = %+v, want from %d with %d steps", report, tt.wantFrom, tt.wantSteps)
+			}
+			h := storage.Devices[testMAC]
+			if h == nil || h.MAC != testMAC || h.Device.Name != "Office" || h.Snapshots == nil || h.Changes == nil || h.Summaries == nil {
+				t.Errorf("decoded history = %+v", h)
+			}
+		})
+	}
+}
+
+func TestMigrateDocumentReportsChangedDevices(t *testing.T) {
+	doc := map[string]any{"devices": map[string]any{
+		"0a:51:00:00:00:01": map[string]any{"mac": "0a:51:00:00:00:01", "snapshots": []any{}, "changes": []any{}, "summaries": []any{}},
+		"0a:51:00:00:00:02": map[string]any{},
+	}}
+	report, err := migrateDocument(doc)
+	if err != nil {
+		t.Fatalf("migrateDocument: %v", err)
+	}
+	if len(report.Steps) != 1 || report.Steps[0].Changed != 1 || report.Devices != 2 {
+		t.Errorf("report = %+v, want one step changing one of two devices", report)
+	}
+	if doc["schema_version"] != SchemaVersion {
+		t.Errorf("schema_version = %v", doc["schema_version"])
+	}
+}
+
+func TestMigrateDryRunLeavesFileUntouched(t *testing.T) {
+	path := filepath.Join(t.TempDir(), StorageFileName)
+	data := []byte(`{"devices": {"0a:51:00:00:00:01": {"device": {"name": "Office"}}}}`)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewJSONStore(path)
+	report, err := store.Migrate(true)
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if report.From != 0 || len(report.Steps) != SchemaVersion {
+		t.Errorf("report = %+v", report)
+	}
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(after, data) {
+		t.Errorf("dry run rewrote the file:\n%s", after)
+	}
+
+	if _, err := store.Migrate(false); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if report, err = store.Migrate(true); err != nil || len(report.Steps) != 0 {
+		t.Errorf("after migrating: report = %+v, err = %v, want no steps", report, err)
+	}
+}
@@ -2,17 +2,25 @@ package storage
 
 import (
 	"time"
+
+	"github.com/romaingallez/clim_cli/internals/api"
 )
 
 // DeviceSnapshot represents a snapshot of device information at a specific point in time
 type DeviceSnapshot struct {
 	IP           string            `json:"ip"`
 	MAC          string            `json:"mac"`
+	Dialect      api.Dialect       `json:"dialect,omitempty"` // Adapter API flavour; empty when never detected
 	Name         string            `json:"name"`
 	Model        string            `json:"model"`
 	Status       string            `json:"status"`
 	BasicInfo    map[string]string `json:"basic_info"`
 	ControlInfo  map[string]string `json:"control_info"`
+	ModelInfo    map[string]string `json:"model_info,omitempty"`
+	SensorInfo   map[string]string `json:"sensor_info,omitempty"`   // Last sensor reading, not tracked as changes
+	SensorReadAt time.Time         `json:"sensor_read_at,omitzero"` // Zero when the unit never answered get_sensor_info
+	// Capabilities is derived from ModelInfo; nil means the defaults apply
+	Capabilities *api.Capabilities `json:"capabilities,omitempty"`
 	DiscoveredAt time.Time         `json:"discovered_at"`
 	LastSeenAt   time.Time         `json:"last_seen_at"`
 }
@@ -23,6 +31,17 @@ type DeviceHistory struct {
 	Device    DeviceSnapshot   `json:"device"`    // Latest snapshot
 	Snapshots []DeviceSnapshot `json:"snapshots"` // All historical snapshots
 	Changes   []DeviceChange   `json:"changes"`   // Detected changes over time
+	// Summaries downsample the snapshots dropped by the retention policy, one per day
+	Summaries []DailySummary `json:"summaries"`
+	// Transport is set for adapters that need more than plain HTTP (e.g. HTTPS with a registered terminal)
+	Transport *DeviceTransport `json:"transport,omitempty"`
+}
+
+// DeviceTransport holds how to reach a device.
+// The adapter key is only needed to register the UUID and is never stored.
+type DeviceTransport struct {
+	Scheme string `json:"scheme"`         // "http" or "https"
+	UUID   string `json:"uuid,omitempty"` // Terminal UUID registered with the adapter (X-Daikin-uuid)
 }
 
 // DeviceChange represents a detected change in device information
@@ -35,6 +54,7 @@ type DeviceChange struct {
 
 // DeviceStorage represents the complete storage structure
 type DeviceStorage struct {
-	Devices     map[string]*DeviceHistory `json:"devices"` // Keyed by MAC address
-	LastUpdated time.Time                 `json:"last_updated"`
+	SchemaVersion int                       `json:"schema_version"` // See SchemaVersion; missing in files written before versioning
+	Devices       map[string]*DeviceHistory `json:"devices"`        // Keyed by MAC address
+	LastUpdated   time.Time                 `json:"last_updated"`
 }
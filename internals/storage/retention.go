@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/romaingallez/clim_cli/internals/config"
+)
+
+// RetentionPolicy limits the archived snapshots of each device.
+// Snapshots dropped by MaxSnapshots or MaxAge are folded into daily summaries.
+type RetentionPolicy struct {
+	MaxSnapshots int           // Archived snapshots kept per device (0 = unlimited)
+	MaxAge       time.Duration // Archived snapshots older than this are dropped (0 = keep forever)
+	OnlyOnChange bool          // Archive the previous snapshot only when a change is detected
+}
+
+// RetentionFromConfig returns the policy configured under storage.retention
+func RetentionFromConfig() RetentionPolicy {
+	cfg := config.GetRetentionConfig()
+	return RetentionPolicy{
+		MaxSnapshots: cfg.MaxSnapshots,
+		MaxAge:       cfg.MaxAge,
+		OnlyOnChange: cfg.OnlyOnChange,
+	}
+}
+
+// Limited reports whether the policy drops snapshots at all
+func (p RetentionPolicy) Limited() bool {
+	return p.MaxSnapshots > 0 || p.MaxAge > 0
+}
+
+// dropped flags the snapshots (oldest first) the policy drops at now
+func (p RetentionPolicy) dropped(snapshots []DeviceSnapshot, now time.Time) []bool {
+	drop := make([]bool, len(snapshots))
+	kept := len(snapshots)
+	if p.MaxAge > 0 {
+		cutoff := now.Add(-p.MaxAge)
+		for i, s := range snapshots {
+			if s.LastSeenAt.Before(cutoff) {
+				drop[i] = true
+				kept--
+			}
+		}
+	}
+	if p.MaxSnapshots > 0 {
+		for i := 0; i < len(snapshots) && kept > p.MaxSnapshots; i++ {
+			if !drop[i] {
+				drop[i] = true
+				kept--
+			}
+		}
+	}
+	return drop
+}
+
+// PruneReport reports a prune run
+type PruneReport struct {
+	Devices   int // Devices with dropped snapshots
+	Dropped   int // Snapshots dropped
+	Kept      int // Snapshots kept
+	Summaries int // Daily summaries created or updated
+}
+
+// DailySummary downsamples the snapshots of a device dropped for one day (local time)
+type DailySummary struct {
+	Date            string         `json:"date"` // 2006-01-02
+	Snapshots       int            `json:"snapshots"`
+	FirstSeenAt     time.Time      `json:"first_seen_at"`
+	LastSeenAt      time.Time      `json:"last_seen_at"`
+	IPs             []string       `json:"ips,omitempty"`
+	PowerOn         int            `json:"power_on"`        // Snapshots with the unit powered on
+	Modes           map[string]int `json:"modes,omitempty"` // Snapshots per control_info mode
+	AvgSetTemp      float64        `json:"avg_set_temp,omitempty"`
+	SetTempSamples  int            `json:"set_temp_samples,omitempty"`
+	AvgRoomTemp     float64        `json:"avg_room_temp,omitempty"`
+	RoomTempSamples int            `json:"room_temp_samples,omitempty"`
+}
+
+// add folds a snapshot into the summary
+func (d *DailySummary) add(s DeviceSnapshot) {
+	if d.Snapshots == 0 || s.LastSeenAt.Before(d.FirstSeenAt) {
+		d.FirstSeenAt = s.LastSeenAt
+	}
+	if s.LastSeenAt.After(d.LastSeenAt) {
+		d.LastSeenAt = s.LastSeenAt
+	}
+	d.Snapshots++
+
+	if s.IP != "" && !slices.Contains(d.IPs, s.IP) {
+		d.IPs = append(d.IPs, s.IP)
+	}
+	if s.ControlInfo["pow"] == "1" {
+		d.PowerOn++
+	}
+	if mode := s.ControlInfo["mode"]; mode != "" {
+		if d.Modes == nil {
+			d.Modes = make(map[string]int)
+		}
+		d.Modes[mode]++
+	}
+	if t, err := strconv.ParseFloat(s.ControlInfo["stemp"], 64); err == nil {
+		d.AvgSetTemp = (d.AvgSetTemp*float64(d.SetTempSamples) + t) / float64(d.SetTempSamples+1)
+		d.SetTempSamples++
+	}
+	if t, err := strconv.ParseFloat(s.SensorInfo["htemp"], 64); err == nil {
+		d.AvgRoomTemp = (d.AvgRoomTemp*float64(d.RoomTempSamples) + t) / float64(d.RoomTempSamples+1)
+		d.RoomTempSamples++
+	}
+}
+
+// summarize folds the dropped snapshots into the daily summaries and returns the summaries
+// that were created or updated, sorted by date
+func summarize(existing []DailySummary, dropped []DeviceSnapshot) []DailySummary {
+	byDate := make(map[string]*DailySummary)
+	for i := range existing {
+		byDate[existing[i].Date] = &existing[i]
+	}
+	touched := make(map[string]*DailySummary)
+	for _, s := range dropped {
+		date := s.LastSeenAt.Local().Format("2006-01-02")
+		summary, ok := touched[date]
+		if !ok {
+			summary = &DailySummary{Date: date}
+			if old, ok := byDate[date]; ok {
+				*summary = *old
+				summary.IPs = append([]string(nil), old.IPs...)
+				summary.Modes = make(map[string]int, len(old.Modes))
+				for k, v := range old.Modes {
+					summary.Modes[k] = v
+				}
+			}
+			touched[date] = summary
+		}
+		summary.add(s)
+	}
+
+	summaries := make([]DailySummary, 0, len(touched))
+	for _, s := range touched {
+		summaries = append(summaries, *s)
+	}
+	sortSummaries(summaries)
+	return summaries
+}
+
+// mergeSummaries replaces the summaries of the same dates and keeps them sorted
+func mergeSummaries(existing, updated []DailySummary) []DailySummary {
+	byDate := make(map[string]DailySummary, len(existing)+len(updated))
+	for _, s := range existing {
+		byDate[s.Date] = s
+	}
+	for _, s := range updated {
+		byDate[s.Date] = s
+	}
+	merged := make([]DailySummary, 0, len(byDate))
+	for _, s := range byDate {
+		merged = append(merged, s)
+	}
+	sortSummaries(merged)
+	return merged
+}
+
+func sortSummaries(summaries []DailySummary) {
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Date < summaries[j].Date })
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRetentionPolicyDropped(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+	// One snapshot per hour, the oldest 4 hours ago
+	snapshots := make([]DeviceSnapshot, 4)
+	for i := range snapshots {
+		snapshots[i].LastSeenAt = now.Add(time.Duration(i-4) * time.Hour)
+	}
+
+	tests := []struct {
+		name   string
+		policy RetentionPolicy
+		want   []bool
+	}{
+		{"unlimited", RetentionPolicy{}, []bool{false, false, false, false}},
+		{"max age", RetentionPolicy{MaxAge: 150 * time.Minute}, []bool{true, true, false, false}},
+		{"max snapshots", RetentionPolicy{MaxSnapshots: 1}, []bool{true, true, true, false}},
+		{"max snapshots counts what max age keeps", RetentionPolicy{MaxAge: 150 * time.Minute, MaxSnapshots: 1}, []bool{true, true, true, false}},
+		{"max age already below max snapshots", RetentionPolicy{MaxAge: 150 * time.Minute, MaxSnapshots: 3}, []bool{true, true, false, false}},
+		{"max age drops more than max snapshots", RetentionPolicy{MaxAge: 90 * time.Minute, MaxSnapshots: 2}, []bool{true, true, true, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.dropped(snapshots, now); !slices.Equal(got, tt.want) {
+				t.Errorf("dropped = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizeMergesIntoExistingDay(t *testing.T) {
+	at := func(day, hour int) time.Time { return time.Date(2026, 3, day, hour, 0, 0, 0, time.Local) }
+	existing := []DailySummary{
+		{Date: "2026-03-09", Snapshots: 1, FirstSeenAt: at(9, 8), LastSeenAt: at(9, 8)},
+		{
+			Date: "2026-03-10", Snapshots: 2, FirstSeenAt: at(10, 8), LastSeenAt: at(10, 9),
+			IPs: []string{"10.0.0.1"}, PowerOn: 1, Modes: map[string]int{"4": 2},
+			AvgSetTemp: 20, SetTempSamples: 2,
+		},
+	}
+	dropped := []DeviceSnapshot{
+		{IP: "10.0.0.2", LastSeenAt: at(10, 10), ControlInfo: map[string]string{"pow": "1", "mode": "4", "stemp": "23"}},
+		{IP: "10.0.0.2", LastSeenAt: at(10, 7), ControlInfo: map[string]string{"pow": "0", "mode": "1"}},
+		{IP: "10.0.0.1", LastSeenAt: at(11, 6), ControlInfo: map[string]string{"pow": "1"}},
+	}
+
+	summaries := summarize(existing, dropped)
+	if len(summaries) != 2 || summaries[0].Date != "2026-03-10" || summaries[1].Date != "2026-03-11" {
+		t.Fatalf("summaries = %+v, want the updated 2026-03-10 and a new 2026-03-11", summaries)
+	}
+	got := summaries[0]
+	if got.Snapshots != 4 || !got.FirstSeenAt.Equal(at(10, 7)) || !got.LastSeenAt.Equal(at(10, 10)) {
+		t.Errorf("merged summary spans %d snapshots from %s to %s", got.Snapshots, got.FirstSeenAt, got.LastSeenAt)
+	}
+	if !slices.Equal(got.IPs, []string{"10.0.0.1", "10.0.0.2"}) || got.PowerOn != 2 {
+		t.Errorf("merged summary IPs = %v, power on = %d", got.IPs, got.PowerOn)
+	}
+	if got.Modes["4"] != 3 || got.Modes["1"] != 1 {
+		t.Errorf("merged summary modes = %v", got.Modes)
+	}
+	if got.AvgSetTemp != 21 || got.SetTempSamples != 3 {
+		t.Errorf("merged summary set temp = %v over %d samples, want 21 over 3", got.AvgSetTemp, got.SetTempSamples)
+	}
+	if summaries[1].Snapshots != 1 || summaries[1].PowerOn != 1 {
+		t.Errorf("new summary = %+v", summaries[1])
+	}
+
+	// The existing summaries are left as they were
+	if existing[1].Snapshots != 2 || len(existing[1].IPs) != 1 || existing[1].Modes["4"] != 2 {
+		t.Errorf("existing summary modified: %+v", existing[1])
+	}
+}
@@ -1,20 +1,22 @@
 package storage
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"sort"
-	"strings"
 	"time"
 
+	"github.com/romaingallez/clim_cli/internals/api"
 	"github.com/romaingallez/clim_cli/internals/search"
 )
 
 const (
 	StorageFileName = "devices.json"
+	// StoreFileMode keeps the storage files private: they hold the terminal UUIDs of registered adapters
+	StoreFileMode = 0600
 )
 
 // GetStoragePath returns the path to the storage file
@@ -32,147 +34,320 @@ func GetStoragePath() (string, error) {
 	return filepath.Join(climDir, StorageFileName), nil
 }
 
-// LoadDeviceStorage loads the device storage from file
+// LoadDeviceStorage loads every device history, snapshots included, from the configured store.
+//
+// Deprecated: use Open and the Store methods, which do not load the whole history.
 func LoadDeviceStorage() (*DeviceStorage, error) {
-	path, err := GetStoragePath()
+	storage := &DeviceStorage{SchemaVersion: SchemaVersion, Devices: make(map[string]*DeviceHistory), LastUpdated: time.Now()}
+	err := withStore(func(store Store) error {
+		histories, err := store.List()
+		if err != nil {
+			return err
+		}
+		for _, h := range histories {
+			if h.Snapshots, err = store.Snapshots(h.MAC); err != nil {
+				return err
+			}
+			storage.Devices[h.MAC] = h
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	// Check if file exists
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		// Return empty storage if file doesn't exist
-		return &DeviceStorage{
-			Devices:     make(map[string]*DeviceHistory),
-			LastUpdated: time.Now(),
-		}, nil
-	}
-
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read storage file: %v", err)
-	}
-
-	var storage DeviceStorage
-	if err := json.Unmarshal(data, &storage); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal storage data: %v", err)
-	}
-
-	// Initialize devices map if nil
-	if storage.Devices == nil {
-		storage.Devices = make(map[string]*DeviceHistory)
-	}
-
-	return &storage, nil
+	return storage, nil
 }
 
-// SaveDeviceStorage saves the device storage to file
+// SaveDeviceStorage replaces the stored history of every device of storage.
+// Devices missing from storage are kept.
+//
+// Deprecated: use Open and Store.Write.
 func SaveDeviceStorage(storage *DeviceStorage) error {
-	path, err := GetStoragePath()
-	if err != nil {
-		return err
-	}
-
-	storage.LastUpdated = time.Now()
-
-	data, err := json.MarshalIndent(storage, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal storage data: %v", err)
-	}
-
-	if err := os.WriteFile(path, data, 0644); err != nil {
-		return fmt.Errorf("failed to write storage file: %v", err)
+	updates := make([]Update, 0, len(storage.Devices))
+	for _, h := range storage.Devices {
+		updates = append(updates, Update{History: h, Snapshots: h.Snapshots, Changes: h.Changes, Summaries: h.Summaries, Replace: true})
 	}
-
-	log.Printf("Saved device storage to %s", path)
-	return nil
+	return withStore(func(store Store) error {
+		return store.Save(updates...)
+	})
 }
 
-// SaveDevices saves a list of discovered devices to storage
+// SaveDevices saves a list of discovered devices to storage.
+// The archived snapshots are then pruned according to the retention settings, in the same
+// transaction: the store is loaded and written once.
 func SaveDevices(devices []search.Device) error {
-	storage, err := LoadDeviceStorage()
-	if err != nil {
-		return fmt.Errorf("failed to load device storage: %v", err)
-	}
+	policy := RetentionFromConfig()
+	return withStore(func(store Store) error {
+		return store.Write(func(tx Tx) error {
+			return saveDevices(tx, devices, policy)
+		})
+	})
+}
 
+// saveDevices records the devices in tx and prunes the archived snapshots
+func saveDevices(tx Tx, devices []search.Device, policy RetentionPolicy) error {
 	now := time.Now()
 
+	updates := make([]Update, 0, len(devices))
 	for _, device := range devices {
 		snapshot := DeviceSnapshot{
 			IP:           device.IP,
 			MAC:          device.MAC,
+			Dialect:      device.Dialect,
 			Name:         device.Name,
 			Model:        device.Model,
 			Status:       device.Status,
 			BasicInfo:    device.BasicInfo,
 			ControlInfo:  device.ControlInfo,
+			ModelInfo:    device.ModelInfo,
+			SensorInfo:   device.SensorInfo,
+			Capabilities: device.Capabilities,
 			DiscoveredAt: now,
 			LastSeenAt:   now,
 		}
 
+		if len(device.SensorInfo) > 0 {
+			snapshot.SensorReadAt = now
+		}
+
 		// Check if device already exists
-		if history, exists := storage.Devices[device.MAC]; exists {
+		history, err := tx.Get(device.MAC)
+		switch {
+		case err == nil:
 			// Update existing device
 			oldSnapshot := history.Device
-
-			// Add current snapshot to history
-			history.Snapshots = append(history.Snapshots, oldSnapshot)
+			if snapshot.Dialect == "" {
+				snapshot.Dialect = oldSnapshot.Dialect
+			}
+			// A failed sensor or model fetch keeps the last known values
+			if len(snapshot.SensorInfo) == 0 {
+				snapshot.SensorInfo = oldSnapshot.SensorInfo
+				snapshot.SensorReadAt = oldSnapshot.SensorReadAt
+			}
+			if len(snapshot.ModelInfo) == 0 {
+				snapshot.ModelInfo = oldSnapshot.ModelInfo
+				snapshot.Capabilities = oldSnapshot.Capabilities
+				if snapshot.Model == "" {
+					snapshot.Model = oldSnapshot.Model
+				}
+			}
 			history.Device = snapshot
 
-			// Detect changes
-			changes := detectChanges(oldSnapshot, snapshot)
-			history.Changes = append(history.Changes, changes...)
-
+			// Archive the previous snapshot and record the detected changes
+			update := Update{History: history, Changes: detectChanges(oldSnapshot, snapshot)}
+			if !policy.OnlyOnChange || len(update.Changes) > 0 {
+				update.Snapshots = []DeviceSnapshot{oldSnapshot}
+			}
+			updates = append(updates, update)
 			log.Printf("Updated existing device %s (%s)", device.Name, device.MAC)
-		} else {
+		case errors.Is(err, ErrNotFound):
 			// Add new device
-			history := &DeviceHistory{
-				MAC:       device.MAC,
-				Device:    snapshot,
+			updates = append(updates, Update{
+				History:   &DeviceHistory{MAC: device.MAC, Device: snapshot},
 				Snapshots: []DeviceSnapshot{snapshot},
-				Changes:   []DeviceChange{},
-			}
-			storage.Devices[device.MAC] = history
+			})
 			log.Printf("Added new device %s (%s)", device.Name, device.MAC)
+		default:
+			return fmt.Errorf("failed to load device storage: %v", err)
+		}
+	}
+
+	if err := tx.Save(updates...); err != nil {
+		return err
+	}
+	if !policy.Limited() {
+		return nil
+	}
+	report, err := tx.Prune(policy, now)
+	if err != nil {
+		return err
+	}
+	if report.Dropped > 0 {
+		log.Printf("Pruned %d snapshots of %d devices into daily summaries", report.Dropped, report.Devices)
+	}
+	return nil
+}
+
+// SaveSensorReadings stores the latest sensor reading of each device, keyed by MAC address.
+// Readings replace the previous one and are not recorded as changes.
+func SaveSensorReadings(readings map[string]map[string]string) error {
+	return withStore(func(store Store) error {
+		return store.Write(func(tx Tx) error {
+			now := time.Now()
+			var updates []Update
+			for mac, reading := range readings {
+				history, err := tx.Get(mac)
+				if errors.Is(err, ErrNotFound) {
+					continue
+				}
+				if err != nil {
+					return fmt.Errorf("failed to load device storage: %v", err)
+				}
+				history.Device.SensorInfo = reading
+				history.Device.SensorReadAt = now
+				history.Device.LastSeenAt = now
+				updates = append(updates, Update{History: history})
+			}
+			if len(updates) == 0 {
+				return nil
+			}
+			return tx.Save(updates...)
+		})
+	})
+}
+
+// IdentityUpdate is a name and/or group change applied to a device; empty fields are left unchanged
+type IdentityUpdate struct {
+	MAC   string
+	Name  string
+	Group string
+}
+
+// UpdateDeviceIdentities records name and group changes made on the devices.
+// A snapshot and the detected changes are added to each device history.
+func UpdateDeviceIdentities(updates []IdentityUpdate) error {
+	return withStore(func(store Store) error {
+		return store.Write(func(tx Tx) error {
+			return updateIdentities(tx, updates)
+		})
+	})
+}
+
+// updateIdentities applies the identity updates in tx
+func updateIdentities(tx Tx, updates []IdentityUpdate) error {
+	writes := make([]Update, 0, len(updates))
+	for _, update := range updates {
+		history, err := tx.Get(update.MAC)
+		if err != nil {
+			return deviceLookupError(update.MAC, err)
+		}
+
+		oldSnapshot := history.Device
+		snapshot := oldSnapshot
+		snapshot.BasicInfo = make(map[string]string, len(oldSnapshot.BasicInfo))
+		for k, v := range oldSnapshot.BasicInfo {
+			snapshot.BasicInfo[k] = v
+		}
+		if update.Name != "" {
+			snapshot.Name = update.Name
+			snapshot.BasicInfo["name"] = update.Name
 		}
+		if update.Group != "" {
+			snapshot.BasicInfo["grp_name"] = update.Group
+		}
+
+		history.Device = snapshot
+		writes = append(writes, Update{
+			History:   history,
+			Snapshots: []DeviceSnapshot{oldSnapshot},
+			Changes:   detectChanges(oldSnapshot, snapshot),
+		})
+		log.Printf("Updated identity of device %s (%s)", snapshot.Name, update.MAC)
 	}
 
-	return SaveDeviceStorage(storage)
+	return tx.Save(writes...)
+}
+
+// SetDeviceTransport stores how to reach the device with the given MAC address
+func SetDeviceTransport(mac string, transport DeviceTransport) error {
+	return withStore(func(store Store) error {
+		return store.Write(func(tx Tx) error {
+			history, err := tx.Get(mac)
+			if err != nil {
+				return deviceLookupError(mac, err)
+			}
+			history.Transport = &transport
+			return tx.Save(Update{History: history})
+		})
+	})
 }
 
 // GetDeviceHistories returns all device histories sorted by device name
 func GetDeviceHistories() ([]*DeviceHistory, error) {
-	storage, err := LoadDeviceStorage()
+	var histories []*DeviceHistory
+	err := withStore(func(store Store) error {
+		var err error
+		histories, err = store.List()
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	histories := make([]*DeviceHistory, 0, len(storage.Devices))
-	for _, history := range storage.Devices {
-		histories = append(histories, history)
-	}
-
 	// Sort by device name
-	sort.Slice(histories, func(i, j int) bool {
-		return strings.ToLower(histories[i].Device.Name) < strings.ToLower(histories[j].Device.Name)
-	})
-
+	sortByName(histories)
 	return histories, nil
 }
 
-// GetDeviceHistory returns the history for a specific device by MAC address
-func GetDeviceHistory(mac string) (*DeviceHistory, error) {
-	storage, err := LoadDeviceStorage()
+// GetDevicesByGroup returns the devices of a group (grp_name) sorted by device name
+func GetDevicesByGroup(group string) ([]*DeviceHistory, error) {
+	var histories []*DeviceHistory
+	err := withStore(func(store Store) error {
+		var err error
+		histories, err = store.ListByGroup(group)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
+	sortByName(histories)
+	return histories, nil
+}
+
+// GetDeviceHistory returns the history for a specific device by MAC address, snapshots included
+func GetDeviceHistory(mac string) (*DeviceHistory, error) {
+	var history *DeviceHistory
+	err := withStore(func(store Store) error {
+		var err error
+		if history, err = store.Get(mac); err != nil {
+			return deviceLookupError(mac, err)
+		}
+		history.Snapshots, err = store.Snapshots(mac)
+		return err
+	})
+	return history, err
+}
+
+// FindDeviceByIP returns the stored device currently using the given IP address
+func FindDeviceByIP(ip string) (*DeviceHistory, error) {
+	var history *DeviceHistory
+	err := withStore(func(store Store) error {
+		var err error
+		history, err = store.FindByIP(ip)
+		if errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("device with IP %s not found", ip)
+		}
+		return err
+	})
+	return history, err
+}
+
+// deviceLookupError names the MAC address in ErrNotFound
+func deviceLookupError(mac string, err error) error {
+	if errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("device with MAC %s not found", mac)
+	}
+	return err
+}
 
-	history, exists := storage.Devices[mac]
-	if !exists {
-		return nil, fmt.Errorf("device with MAC %s not found", mac)
+// Capabilities returns the stored capability set of the device, or the defaults when unknown
+func (h *DeviceHistory) Capabilities() api.Capabilities {
+	if h == nil || h.Device.Capabilities == nil {
+		return api.DefaultCapabilities()
 	}
+	return *h.Device.Capabilities
+}
 
-	return history, nil
+// DiffSnapshots compares two snapshots field by field, sensor readings included, and
+// returns the differences sorted by field. ChangedAt is the time of the newer snapshot.
+func DiffSnapshots(old, new DeviceSnapshot) []DeviceChange {
+	changes := detectChanges(old, new)
+	changes = append(changes, compareMaps("sensor_info", old.SensorInfo, new.SensorInfo, new.LastSeenAt)...)
+	for i := range changes {
+		changes[i].ChangedAt = new.LastSeenAt
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
 }
 
 // detectChanges compares two device snapshots and returns a list of changes
@@ -208,6 +383,15 @@ func detectChanges(old, new DeviceSnapshot) []DeviceChange {
 		})
 	}
 
+	if old.Dialect != new.Dialect {
+		changes = append(changes, DeviceChange{
+			Field:     "dialect",
+			OldValue:  string(old.Dialect),
+			NewValue:  string(new.Dialect),
+			ChangedAt: now,
+		})
+	}
+
 	if old.Status != new.Status {
 		changes = append(changes, DeviceChange{
 			Field:     "status",
@@ -220,6 +404,7 @@ func detectChanges(old, new DeviceSnapshot) []DeviceChange {
 	// Compare maps
 	changes = append(changes, compareMaps("basic_info", old.BasicInfo, new.BasicInfo, now)...)
 	changes = append(changes, compareMaps("control_info", old.ControlInfo, new.ControlInfo, now)...)
+	changes = append(changes, compareMaps("model_info", old.ModelInfo, new.ModelInfo, now)...)
 
 	return changes
 }
@@ -269,27 +454,17 @@ func compareMaps(prefix string, old, new map[string]string, timestamp time.Time)
 
 // GetRecentChanges returns devices that have changed within the specified duration
 func GetRecentChanges(since time.Duration) ([]*DeviceHistory, error) {
-	storage, err := LoadDeviceStorage()
+	var changed []*DeviceHistory
+	err := withStore(func(store Store) error {
+		var err error
+		changed, err = store.ChangedSince(time.Now().Add(-since))
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	cutoff := time.Now().Add(-since)
-	var changed []*DeviceHistory
-
-	for _, history := range storage.Devices {
-		for _, change := range history.Changes {
-			if change.ChangedAt.After(cutoff) {
-				changed = append(changed, history)
-				break
-			}
-		}
-	}
-
 	// Sort by device name
-	sort.Slice(changed, func(i, j int) bool {
-		return strings.ToLower(changed[i].Device.Name) < strings.ToLower(changed[j].Device.Name)
-	})
-
+	sortByName(changed)
 	return changed, nil
 }
@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/romaingallez/clim_cli/internals/config"
+)
+
+// Storage backends selectable with storage.backend
+const (
+	BackendJSON = "json" // Whole history in devices.json, rewritten on every save
+	BackendBolt = "bolt" // Embedded bbolt database devices.db, indexed by MAC, IP, group and time
+)
+
+// DatabaseFileName is the bbolt database file, next to devices.json
+const DatabaseFileName = "devices.db"
+
+// ErrNotFound is returned when no stored device matches
+var ErrNotFound = errors.New("device not found")
+
+// Update is a write to a device history: the latest snapshot and transport of History
+// replace the stored ones, Snapshots and Changes are appended and Summaries replace the
+// stored summaries of the same dates.
+type Update struct {
+	History   *DeviceHistory
+	Snapshots []DeviceSnapshot
+	Changes   []DeviceChange
+	Summaries []DailySummary
+	Replace   bool // Drop the stored snapshots, changes and summaries first
+}
+
+// Tx reads and writes a store inside Store.Write.
+// Get returns the history as Store.Get does, with the writes made earlier in the transaction.
+type Tx interface {
+	Get(mac string) (*DeviceHistory, error)
+	Save(updates ...Update) error
+	// Prune drops the archived snapshots the policy does not keep at now, as Store.Prune does
+	Prune(policy RetentionPolicy, now time.Time) (*PruneReport, error)
+}
+
+// Store persists device histories.
+// Histories returned by Get, List, FindByIP, ListByGroup and ChangedSince hold the latest
+// snapshot, the transport, the changes and the summaries, but no Snapshots: read them with Snapshots.
+type Store interface {
+	Get(mac string) (*DeviceHistory, error)
+	List() ([]*DeviceHistory, error)
+	FindByIP(ip string) (*DeviceHistory, error)
+	ListByGroup(group string) ([]*DeviceHistory, error)
+	// ChangedSince returns the devices with a change recorded after t
+	ChangedSince(t time.Time) ([]*DeviceHistory, error)
+	// Snapshots returns the archived snapshots of a device, oldest first
+	Snapshots(mac string) ([]DeviceSnapshot, error)
+	// Save applies the updates at once
+	Save(updates ...Update) error
+	// Write runs fn with exclusive access to the store, loading it once: reads and writes in
+	// fn see each other and no other process writes in between. Nothing is written when fn fails.
+	Write(fn func(Tx) error) error
+	// Migrate upgrades the stored data to SchemaVersion; with dryRun it only reports what would change
+	Migrate(dryRun bool) (*MigrationReport, error)
+	// Prune drops the archived snapshots the policy does not keep at now, folding them into
+	// daily summaries; with dryRun it only reports what would be dropped
+	Prune(policy RetentionPolicy, now time.Time, dryRun bool) (*PruneReport, error)
+	Close() error
+}
+
+// Open opens the store selected by storage.backend in the clim_cli config directory.
+// Data written with an older schema is migrated first.
+func Open() (Store, error) {
+	return open(true)
+}
+
+func open(migrate bool) (Store, error) {
+	backend := config.GetStorageBackend()
+	switch backend {
+	case "", BackendJSON:
+		path, err := GetStoragePath()
+		if err != nil {
+			return nil, err
+		}
+		return NewJSONStore(path), nil
+	case BackendBolt:
+		path, err := GetDatabasePath()
+		if err != nil {
+			return nil, err
+		}
+		store, err := OpenBoltStore(path)
+		if err != nil || !migrate {
+			return store, err
+		}
+		// The JSON store migrates on every load; the database is migrated once, in place
+		report, err := store.Migrate(false)
+		if err != nil {
+			store.Close()
+			return nil, err
+		}
+		if len(report.Steps) > 0 {
+			log.Printf("Migrated %s from schema version %d to %d", path, report.From, report.To)
+		}
+		return store, nil
+	}
+	return nil, fmt.Errorf("unknown storage backend %q (want %s or %s)", backend, BackendJSON, BackendBolt)
+}
+
+// GetDatabasePath returns the path to the bbolt database file
+func GetDatabasePath() (string, error) {
+	path, err := GetStoragePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), DatabaseFileName), nil
+}
+
+// withStore opens the configured store, runs fn and closes it
+func withStore(fn func(Store) error) error {
+	return runStore(Open, fn)
+}
+
+// runStore opens a store with openFn, runs fn and closes it
+func runStore(openFn func() (Store, error), fn func(Store) error) error {
+	store, err := openFn()
+	if err != nil {
+		return err
+	}
+	if err := fn(store); err != nil {
+		store.Close()
+		return err
+	}
+	return store.Close()
+}
+
+// MigrateStore runs the migrations of the configured store
+func MigrateStore(dryRun bool) (*MigrationReport, error) {
+	var report *MigrationReport
+	openRaw := func() (Store, error) { return open(false) }
+	err := runStore(openRaw, func(store Store) error {
+		var err error
+		report, err = store.Migrate(dryRun)
+		return err
+	})
+	return report, err
+}
+
+// PruneStore applies the retention policy to the configured store
+func PruneStore(policy RetentionPolicy, dryRun bool) (*PruneReport, error) {
+	var report *PruneReport
+	err := withStore(func(store Store) error {
+		var err error
+		report, err = store.Prune(policy, time.Now(), dryRun)
+		return err
+	})
+	return report, err
+}
+
+// ImportJSON copies every device history of the devices.json file at path into dst,
+// replacing the devices dst already holds under the same MAC address
+func ImportJSON(path string, dst Store) (int, error) {
+	if _, err := os.Stat(path); err != nil {
+		return 0, fmt.Errorf("cannot import %s: %v", path, err)
+	}
+	storage, err := (&jsonStore{path: path}).read()
+	if err != nil {
+		return 0, err
+	}
+
+	updates := make([]Update, 0, len(storage.Devices))
+	for mac, history := range storage.Devices {
+		if history.MAC == "" {
+			history.MAC = mac
+		}
+		updates = append(updates, Update{History: history, Snapshots: history.Snapshots, Changes: history.Changes, Summaries: history.Summaries, Replace: true})
+	}
+	return len(updates), dst.Save(updates...)
+}
+
+// sortByName sorts histories by device name, case-insensitively
+func sortByName(histories []*DeviceHistory) {
+	sort.Slice(histories, func(i, j int) bool {
+		return strings.ToLower(histories[i].Device.Name) < strings.ToLower(histories[j].Device.Name)
+	})
+}
+
+// groupOf returns the group name (grp_name) of the latest snapshot
+func groupOf(h *DeviceHistory) string {
+	return h.Device.BasicInfo["grp_name"]
+}
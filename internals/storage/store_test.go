@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+const testMAC = "0a:51:00:00:00:01"
+
+// addBasicInfo sets key in the basic_info of the test device with a read-modify-write in one transaction
+func addBasicInfo(store Store, key string) error {
+	return store.Write(func(tx Tx) error {
+		history, err := tx.Get(testMAC)
+		if err != nil {
+			return err
+		}
+		history.Device.BasicInfo = maps.Clone(history.Device.BasicInfo)
+		history.Device.BasicInfo[key] = "1"
+		return tx.Save(Update{History: history})
+	})
+}
+
+// checkConcurrentWrites runs writers concurrent read-modify-writes, each with the store returned
+// by storeFor, and checks that none of them was lost
+func checkConcurrentWrites(t *testing.T, seed Store, storeFor func() Store) {
+	t.Helper()
+	const writers = 20
+	err := seed.Save(Update{History: &DeviceHistory{MAC: testMAC, Device: DeviceSnapshot{MAC: testMAC, BasicInfo: map[string]string{}}}})
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	errs := make(chan error, writers)
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			errs <- addBasicInfo(storeFor(), fmt.Sprintf("k%d", i))
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	history, err := seed.Get(testMAC)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if n := len(history.Device.BasicInfo); n != writers {
+		t.Errorf("basic_info has %d keys after %d writes: updates were lost", n, writers)
+	}
+}
+
+func TestJSONStoreWriteIsAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), StorageFileName)
+	// One store per writer, like separate clim_cli processes sharing devices.json
+	checkConcurrentWrites(t, NewJSONStore(path), func() Store { return NewJSONStore(path) })
+}
+
+func TestBoltStoreWriteIsAtomic(t *testing.T) {
+	store, err := OpenBoltStore(filepath.Join(t.TempDir(), DatabaseFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	checkConcurrentWrites(t, store, func() Store { return store })
+}
@@ -32,17 +32,31 @@ const (
 	focusTemp
 	focusFanRate
 	focusFanDir
+	focusSpecial
 	focusDevice
+	focusCount
 )
 
 type fetchMsg struct{}
 
+// applyDoneMsg carries the per-device results of applyAll
+type applyDoneMsg struct {
+	results []string
+}
+
+// verifyTimeout bounds the read-back of the applied settings on each device
+const verifyTimeout = 8 * time.Second
+
 type controlModel struct {
+	client       *api.Client
 	devices      []*storage.DeviceHistory
 	cursorDevice int
 	focus        controlFocus
 	current      map[string]string
+	sensors      map[string]string
 	pending      api.Clim
+	specialKind  int                      // Index in api.SpecialModes of the special mode row
+	special      map[api.SpecialMode]bool // Staged special modes; absent means unchanged
 	showHelp     bool
 	showConfirm  bool
 	showResults  bool
@@ -51,14 +65,14 @@ type controlModel struct {
 	quitting     bool
 }
 
-func newControlModel(devs []*storage.DeviceHistory) controlModel {
+func newControlModel(client *api.Client, devs []*storage.DeviceHistory) controlModel {
 	// ensure stable order by name
 	sorted := make([]*storage.DeviceHistory, len(devs))
 	copy(sorted, devs)
 	sort.Slice(sorted, func(i, j int) bool {
 		return strings.ToLower(sorted[i].Device.Name) < strings.ToLower(sorted[j].Device.Name)
 	})
-	m := controlModel{devices: sorted, current: map[string]string{}}
+	m := controlModel{client: client, devices: sorted, current: map[string]string{}, sensors: map[string]string{}, special: map[api.SpecialMode]bool{}}
 	if len(sorted) > 0 {
 		ip := sorted[0].Device.IP
 		m.pending.IP = ip
@@ -98,9 +112,9 @@ func (m controlModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "h":
 			m.showHelp = !m.showHelp
 		case "tab":
-			m.focus = (m.focus + 1) % 6
+			m.focus = (m.focus + 1) % focusCount
 		case "shift+tab":
-			m.focus = (m.focus + 5) % 6
+			m.focus = (m.focus + focusCount - 1) % focusCount
 		case "r":
 			return m, func() tea.Msg { return fetchMsg{} }
 		case "+":
@@ -137,6 +151,8 @@ func (m controlModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.cycleFanRate(true)
 		case "d":
 			m.cycleFanDir(true)
+		case "s":
+			m.toggleSpecial()
 		case "a":
 			if len(m.devices) == 0 {
 				break
@@ -152,53 +168,76 @@ func (m controlModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.showConfirm = false
 			}
 		}
+	case applyDoneMsg:
+		m.applyResults = msg.results
+		m.showResults = true
+		return m, nil
 	case fetchMsg:
 		if len(m.devices) == 0 {
 			return m, tea.Tick(2*time.Second, func(time.Time) tea.Msg { return fetchMsg{} })
 		}
 		ip := m.devices[m.cursorDevice].Device.IP
-		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		// Each refresh gets its own retry budget, so failures early in a session do not disable retries
+		ctx, cancel := context.WithTimeout(m.client.WithRetryBudget(context.Background()), 3*time.Second)
 		defer cancel()
-		info, err := api.FetchControlInfo(ctx, ip)
+		info, err := m.client.FetchControlInfo(ctx, ip)
 		if err != nil {
 			m.err = err
 		} else {
 			m.err = nil
-			m.current = info
-			if v, ok := info["pow"]; ok {
-				m.pending.Power = v
+			m.current = info.Values
+			cur := info.Clim(ip)
+			if cur.Power != "" {
+				m.pending.Power = cur.Power
+			}
+			if cur.Mode != "" {
+				m.pending.Mode = cur.Mode
 			}
-			if v, ok := info["mode"]; ok {
-				m.pending.Mode = v
+			if cur.Temp != "" {
+				m.pending.Temp = cur.Temp
 			}
-			if v, ok := info["stemp"]; ok {
-				m.pending.Temp = v
+			if cur.Shum != "" {
+				m.pending.Shum = cur.Shum
 			}
-			if v, ok := info["f_rate"]; ok {
-				m.pending.FanRate = v
+			if cur.FanRate != "" {
+				m.pending.FanRate = cur.FanRate
 			}
-			if v, ok := info["f_dir"]; ok {
-				m.pending.FanDir = v
+			if cur.FanDir != "" {
+				m.pending.FanDir = cur.FanDir
 			}
 		}
+		if sensors, err := m.client.FetchSensorInfo(ctx, ip); err == nil {
+			m.sensors = sensors.Values
+		} else {
+			m.sensors = map[string]string{}
+		}
 		return m, tea.Tick(2*time.Second, func(time.Time) tea.Msg { return fetchMsg{} })
 	}
 	return m, nil
 }
 
 func (m *controlModel) incrementTemp(delta int) {
-	val, err := strconv.Atoi(m.pending.Temp)
+	caps := m.capabilities()
+	val, err := strconv.ParseFloat(m.pending.Temp, 64)
 	if err != nil {
 		val = 24
 	}
-	val += delta
-	if val < 16 {
-		val = 16
+	val += float64(delta)
+	if val < caps.TempMin {
+		val = caps.TempMin
+	}
+	if val > caps.TempMax {
+		val = caps.TempMax
 	}
-	if val > 30 {
-		val = 30
+	m.pending.Temp = strconv.FormatFloat(val, 'f', -1, 64)
+}
+
+// capabilities returns the capability set of the focused device
+func (m controlModel) capabilities() api.Capabilities {
+	if len(m.devices) == 0 {
+		return api.DefaultCapabilities()
 	}
-	m.pending.Temp = strconv.Itoa(val)
+	return m.devices[m.cursorDevice].Capabilities()
 }
 
 func (m *controlModel) togglePower() {
@@ -210,18 +249,40 @@ func (m *controlModel) togglePower() {
 }
 
 func (m *controlModel) cycleMode(forward bool) {
-	seq := []string{"0", "1", "2", "3", "4"}
-	m.pending.Mode = cycle(seq, m.pending.Mode, forward)
+	m.pending.Mode = cycle(m.capabilities().Modes, m.pending.Mode, forward)
 }
 
 func (m *controlModel) cycleFanRate(forward bool) {
-	seq := []string{"A", "3", "4", "5", "6", "7"}
-	m.pending.FanRate = cycle(seq, m.pending.FanRate, forward)
+	m.pending.FanRate = cycle(m.capabilities().FanRates, m.pending.FanRate, forward)
 }
 
 func (m *controlModel) cycleFanDir(forward bool) {
-	seq := []string{"0", "1", "2", "3"}
-	m.pending.FanDir = cycle(seq, m.pending.FanDir, forward)
+	m.pending.FanDir = cycle(m.capabilities().FanDirs, m.pending.FanDir, forward)
+}
+
+// toggleSpecial cycles the staged state of the selected special mode: unchanged → on → off → unchanged
+func (m *controlModel) toggleSpecial() {
+	mode := api.SpecialModes[m.specialKind]
+	on, staged := m.special[mode]
+	switch {
+	case !staged:
+		m.special[mode] = true
+	case on:
+		m.special[mode] = false
+	default:
+		delete(m.special, mode)
+	}
+}
+
+// stagedSpecials returns the staged special modes in api.SpecialModes order
+func (m controlModel) stagedSpecials() []api.SpecialModeSetting {
+	var settings []api.SpecialModeSetting
+	for _, mode := range api.SpecialModes {
+		if on, ok := m.special[mode]; ok {
+			settings = append(settings, api.SpecialModeSetting{Mode: mode, On: on})
+		}
+	}
+	return settings
 }
 
 func (m *controlModel) cycleField(forward bool) {
@@ -234,10 +295,20 @@ func (m *controlModel) cycleField(forward bool) {
 		m.cycleFanDir(forward)
 	case focusPower:
 		m.togglePower()
+	case focusSpecial:
+		n := len(api.SpecialModes)
+		if forward {
+			m.specialKind = (m.specialKind + 1) % n
+		} else {
+			m.specialKind = (m.specialKind + n - 1) % n
+		}
 	}
 }
 
 func cycle(seq []string, cur string, forward bool) string {
+	if len(seq) == 0 {
+		return cur
+	}
 	idx := 0
 	for i, v := range seq {
 		if v == cur {
@@ -285,8 +356,12 @@ func (m controlModel) renderTopStatus() string {
 		}
 		return "?"
 	}
-	line := fmt.Sprintf("Focus: %s (%s) | pow=%s mode=%s stemp=%s f_rate=%s f_dir=%s",
-		name, ip, cur("pow"), cur("mode"), cur("stemp"), cur("f_rate"), cur("f_dir"))
+	room := "?"
+	if v, ok := m.sensors["htemp"]; ok {
+		room = v
+	}
+	line := fmt.Sprintf("Focus: %s (%s) | pow=%s mode=%s stemp=%s htemp=%s f_rate=%s f_dir=%s",
+		name, ip, cur("pow"), cur("mode"), cur("stemp"), room, cur("f_rate"), cur("f_dir"))
 	if m.err != nil {
 		line += "  " + ctrlErrStyle.Render(m.err.Error())
 	}
@@ -317,9 +392,36 @@ func (m controlModel) renderControls() string {
 	row("Temp", m.current["stemp"], m.pending.Temp, m.focus == focusTemp)
 	row("FanRate", m.current["f_rate"], m.pending.FanRate, m.focus == focusFanRate)
 	row("FanDir", m.current["f_dir"], m.pending.FanDir, m.focus == focusFanDir)
+	row("Special", m.currentSpecials(), m.renderSpecial(), m.focus == focusSpecial)
 	return sb.String()
 }
 
+// currentSpecials lists the active special modes of the focused device
+func (m controlModel) currentSpecials() string {
+	active := api.ControlInfo{Advanced: m.current["adv"]}.ActiveSpecialModes()
+	if len(active) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(active))
+	for _, mode := range active {
+		names = append(names, string(mode))
+	}
+	return strings.Join(names, ",")
+}
+
+// renderSpecial shows the selected special mode row and the staged changes
+func (m controlModel) renderSpecial() string {
+	mode := api.SpecialModes[m.specialKind]
+	state := "-"
+	if on, ok := m.special[mode]; ok {
+		state = "Off"
+		if on {
+			state = "On"
+		}
+	}
+	return fmt.Sprintf("<%s> %s", mode, state)
+}
+
 func (m controlModel) renderDeviceList() string {
 	var sb strings.Builder
 	sb.WriteString(ctrlLabelStyle.Render("Selected Devices"))
@@ -342,15 +444,19 @@ func (m controlModel) renderDeviceList() string {
 
 func (m controlModel) renderHelp() string {
 	if !m.showHelp {
-		return ctrlDimStyle.Render("Tab/Shift+Tab focus • ↑/↓ navigate • ←/→ cycle • p power • m mode • +/- temp • f fan rate • d fan dir • r refresh • a apply • h help • q quit")
+		return ctrlDimStyle.Render("Tab/Shift+Tab focus • ↑/↓ navigate • ←/→ cycle • p power • m mode • +/- temp • f fan rate • d fan dir • s special • r refresh • a apply • h help • q quit")
 	}
-	return ctrlDimStyle.Render("Keybindings:\n  Navigation: Tab/Shift+Tab, ↑/↓, ←/→\n  Power: p\n  Mode: m (or ←/→ on Mode)\n  Temp: +/- or ↑/↓ when Temp focused\n  Fan Rate: f (or ←/→ on FanRate)\n  Fan Dir: d (or ←/→ on FanDir)\n  Refresh: r\n  Apply all: a (then y/n)\n  Help: h\n  Close modal: Esc\n  Quit: q")
+	return ctrlDimStyle.Render("Keybindings:\n  Navigation: Tab/Shift+Tab, ↑/↓, ←/→\n  Power: p\n  Mode: m (or ←/→ on Mode)\n  Temp: +/- or ↑/↓ when Temp focused\n  Fan Rate: f (or ←/→ on FanRate)\n  Fan Dir: d (or ←/→ on FanDir)\n  Special: ←/→ on Special to pick, s to toggle on/off/unchanged\n  Refresh: r\n  Apply all: a (then y/n)\n  Help: h\n  Close modal: Esc\n  Quit: q")
 }
 
 func (m controlModel) renderConfirm() string {
 	count := len(m.devices)
-	msg := fmt.Sprintf("Apply to %d device(s)? pow=%s mode=%s stemp=%s f_rate=%s f_dir=%s  [y/n]",
+	msg := fmt.Sprintf("Apply to %d device(s)? pow=%s mode=%s stemp=%s f_rate=%s f_dir=%s",
 		count, m.pending.Power, m.pending.Mode, m.pending.Temp, m.pending.FanRate, m.pending.FanDir)
+	for _, special := range m.stagedSpecials() {
+		msg += " " + special.String()
+	}
+	msg += "  [y/n]"
 	return ctrlSelectStyle.Render(msg)
 }
 
@@ -378,36 +484,67 @@ func (m controlModel) applyAll() tea.Cmd {
 		var wg sync.WaitGroup
 		mu := sync.Mutex{}
 		res := make([]string, 0, len(m.devices))
+		specials := m.stagedSpecials()
+		opCtx := m.client.WithRetryBudget(context.Background())
 		wg.Add(len(m.devices))
 		for _, d := range m.devices {
 			devIP := d.Device.IP
+			caps := d.Capabilities()
 			go func(ip string) {
 				defer wg.Done()
-				ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+				ctx, cancel := context.WithTimeout(opCtx, 4*time.Second)
 				defer cancel()
 				cl := m.pending
 				cl.IP = ip
-				if err := api.SetClim(ctx, cl); err != nil {
+				err := caps.Validate(cl)
+				for _, special := range specials {
+					if err == nil {
+						err = caps.ValidateSpecialMode(special)
+					}
+				}
+				if err != nil {
+					mu.Lock()
+					res = append(res, fmt.Sprintf("SKIP %s: %v", ip, err))
+					mu.Unlock()
+					return
+				}
+				if err := m.client.SetClim(ctx, cl); err != nil {
 					mu.Lock()
 					res = append(res, fmt.Sprintf("ERR %s: %v", ip, err))
 					mu.Unlock()
 					return
 				}
+				for _, special := range specials {
+					if err := m.client.SetSpecialMode(ctx, ip, special); err != nil {
+						mu.Lock()
+						res = append(res, fmt.Sprintf("ERR %s: special mode %s: %v", ip, special, err))
+						mu.Unlock()
+						return
+					}
+				}
+				// Read the settings back: adapters may accept a command the unit then ignores
+				vctx, vcancel := context.WithTimeout(opCtx, verifyTimeout)
+				defer vcancel()
+				if err := m.client.VerifyClim(vctx, cl, api.DefaultVerifyInterval); err != nil {
+					mu.Lock()
+					res = append(res, fmt.Sprintf("MISMATCH %v", err))
+					mu.Unlock()
+					return
+				}
 				mu.Lock()
 				res = append(res, fmt.Sprintf("OK %s", ip))
 				mu.Unlock()
 			}(devIP)
 		}
 		wg.Wait()
-		m.applyResults = res
-		m.showResults = true
-		return nil
+		sort.Strings(res)
+		return applyDoneMsg{results: res}
 	}
 }
 
 // RunControlScreen runs the interactive control UI
-func RunControlScreen(devs []*storage.DeviceHistory) error {
-	model := newControlModel(devs)
+func RunControlScreen(client *api.Client, devs []*storage.DeviceHistory) error {
+	model := newControlModel(client, devs)
 	p := tea.NewProgram(model)
 	_, err := p.Run()
 	return err
@@ -173,6 +173,9 @@ func (m Model) renderListView() string {
 				device.Device.IP,
 				formatLastSeen(device.Device.LastSeenAt),
 			)
+			if temps := formatTemperatures(device.Device); temps != "" {
+				line += " - " + temps
+			}
 
 			if i == m.cursor {
 				b.WriteString(selectedStyle.Render(line))
@@ -234,6 +237,19 @@ func (m Model) renderDetailView() string {
 		b.WriteString("\n")
 	}
 
+	// Last sensor reading
+	if len(device.Device.SensorInfo) > 0 {
+		b.WriteString(lipgloss.NewStyle().Bold(true).Render("Last Sensor Reading:"))
+		b.WriteString("\n")
+		for key, value := range device.Device.SensorInfo {
+			b.WriteString(fmt.Sprintf("  %s: %s\n", key, value))
+		}
+		if !device.Device.SensorReadAt.IsZero() {
+			b.WriteString(fmt.Sprintf("  read at: %s\n", device.Device.SensorReadAt.Format("2006-01-02 15:04:05")))
+		}
+		b.WriteString("\n")
+	}
+
 	// Recent changes
 	if len(device.Changes) > 0 {
 		b.WriteString(lipgloss.NewStyle().Bold(true).Render("Recent Changes:"))
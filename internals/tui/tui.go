@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/romaingallez/clim_cli/internals/storage"
@@ -74,6 +75,9 @@ func PrintDeviceSummary() error {
 		fmt.Printf("   MAC: %s\n", device.Device.MAC)
 		fmt.Printf("   Status: %s\n", device.Device.Status)
 		fmt.Printf("   Last Seen: %s\n", device.Device.LastSeenAt.Format("2006-01-02 15:04:05"))
+		if temps := formatTemperatures(device.Device); temps != "" {
+			fmt.Printf("   Temperature: %s\n", temps)
+		}
 
 		if len(device.Changes) > 0 {
 			fmt.Printf("   Changes: %d detected\n", len(device.Changes))
@@ -92,3 +96,15 @@ func PrintDeviceSummary() error {
 
 	return nil
 }
+
+// formatTemperatures returns "room X°C / set Y°C" from the stored snapshot, omitting unknown values
+func formatTemperatures(snap storage.DeviceSnapshot) string {
+	var parts []string
+	if v := snap.SensorInfo["htemp"]; v != "" && v != "-" && v != "--" {
+		parts = append(parts, fmt.Sprintf("room %s°C", v))
+	}
+	if v := snap.ControlInfo["stemp"]; v != "" && v != "-" && v != "--" && v != "M" {
+		parts = append(parts, fmt.Sprintf("set %s°C", v))
+	}
+	return strings.Join(parts, " / ")
+}